@@ -11,7 +11,6 @@ import (
 	"time"
 
 	blckChain "github.com/DeNetPRO/src/blockchain_provider"
-	"github.com/DeNetPRO/src/cleaner"
 	"github.com/DeNetPRO/src/networks"
 	nodeFile "github.com/DeNetPRO/src/node_file"
 	tstpkg "github.com/DeNetPRO/src/tst_pkg"
@@ -29,6 +28,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -87,7 +87,8 @@ func Create(password string) (string, nodeTypes.Config, error) {
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
 // Import is used for importing crypto wallet. Private key is needed.
-func Import() (string, nodeTypes.Config, error) {
+// Returned password is the one that unlocks imported account.
+func Import() (string, string, nodeTypes.Config, error) {
 	const location = "account.Import->"
 	var nodeConfig nodeTypes.Config
 
@@ -103,7 +104,7 @@ func Import() (string, nodeTypes.Config, error) {
 
 		bytesPrivKey, err := gopass.GetPasswdMasked()
 		if err != nil {
-			return "", nodeConfig, logger.MarkLocation(location, err)
+			return "", "", nodeConfig, logger.MarkLocation(location, err)
 		}
 
 		privKey = string(bytesPrivKey)
@@ -113,7 +114,7 @@ func Import() (string, nodeTypes.Config, error) {
 		for {
 			bytePassword, err := gopass.GetPasswdMasked()
 			if err != nil {
-				return "", nodeConfig, logger.MarkLocation(location, err)
+				return "", "", nodeConfig, logger.MarkLocation(location, err)
 			}
 
 			originalPassword = string(bytePassword)
@@ -131,7 +132,7 @@ func Import() (string, nodeTypes.Config, error) {
 
 	err = paths.CreateAccDirs()
 	if err != nil {
-		return "", nodeConfig, logger.MarkLocation(location, err)
+		return "", "", nodeConfig, logger.MarkLocation(location, err)
 	}
 
 	scryptN, scryptP := encryption.GetScryptParams()
@@ -140,23 +141,20 @@ func Import() (string, nodeTypes.Config, error) {
 
 	ecdsaPrivKey, err := crypto.HexToECDSA(privKey)
 	if err != nil {
-		return "", nodeConfig, logger.MarkLocation(location, err)
+		return "", "", nodeConfig, logger.MarkLocation(location, err)
 	}
 
 	nodeAccount, err := ks.ImportECDSA(ecdsaPrivKey, password)
 	if err != nil {
-		return "", nodeConfig, logger.MarkLocation(location, err)
+		return "", "", nodeConfig, logger.MarkLocation(location, err)
 	}
 
 	nodeConfig, err = makeAccount(ks, &nodeAccount, password)
 	if err != nil {
-		return "", nodeConfig, logger.MarkLocation(location, err)
+		return "", "", nodeConfig, logger.MarkLocation(location, err)
 	}
 
-	go blckChain.StartMakingProofs(nodeAccount.Address, password, nodeConfig)
-	go cleaner.Start()
-
-	return nodeAccount.Address.String(), nodeConfig, nil
+	return nodeAccount.Address.String(), password, nodeConfig, nil
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -215,6 +213,37 @@ func Login(nodeAddr, password string) (*accounts.Account, error) {
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
+// Sign signs hash with private key of the account that is logged in.
+func Sign(hash []byte) ([]byte, error) {
+	const location = "account.Sign->"
+
+	if encryptedPK == nil {
+		return nil, logger.MarkLocation(location, errors.New("account is not logged in"))
+	}
+
+	secretKeyHash := sha256.Sum256(secretKey)
+
+	privateKeyBytes, err := encryption.DecryptAES(secretKeyHash[:], encryptedPK)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	// key is stored without leading zeros
+	privateKey, err := crypto.ToECDSA(common.LeftPadBytes(privateKeyBytes, 32))
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	signature, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	return signature, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
 // Unlock asks user for password and checks it.
 func Unlock() (*accounts.Account, string, error) {
 	const location = "account.Unlock->"
@@ -93,7 +93,7 @@ func TestAccountLogin(t *testing.T) {
 }
 
 func TestImportAccount(t *testing.T) { //TODO add test checks
-	accountAddress, _, err := account.Import()
+	accountAddress, _, _, err := account.Import()
 	if err != nil {
 		t.Fatal(err)
 	}
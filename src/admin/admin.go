@@ -0,0 +1,332 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	blckChain "github.com/DeNetPRO/src/blockchain_provider"
+	"github.com/DeNetPRO/src/cleaner"
+	"github.com/DeNetPRO/src/health"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/networks"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/probe"
+	"github.com/DeNetPRO/src/quota"
+	"github.com/DeNetPRO/src/reload"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Status is served on /status.
+type Status struct {
+	health.Status
+	ProofsPaused bool `json:"proofsPaused"`
+}
+
+// NetworkUsage sizes are set in bytes.
+type NetworkUsage struct {
+	Used             int64            `json:"used"`
+	StorageProviders map[string]int64 `json:"storageProviders"`
+}
+
+// StorageUsage is served on /storage/usage, sizes are set in bytes.
+type StorageUsage struct {
+	Limit    int64                   `json:"limit"`
+	Reserved int64                   `json:"reserved"`
+	Free     uint64                  `json:"free"`
+	Networks map[string]NetworkUsage `json:"networks"`
+}
+
+var (
+	ErrNotLoopback = errors.New("admin api can be served only on loopback address")
+	ErrSocketInUse = errors.New("admin socket is in use, another node may be running")
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Serve serves admin api until ctx is done.
+func Serve(ctx context.Context, conf nodeTypes.AdminConfig) error {
+	const location = "admin.Serve->"
+
+	lis, err := listen(conf.Address)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	health.Register(health.Admin, 0)
+
+	server := &http.Server{
+		Handler:           Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- server.Serve(lis)
+	}()
+
+	select {
+	case err = <-serveErr:
+		return logger.MarkLocation(location, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err = server.Shutdown(shutdownCtx)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// listen listens on unix socket in work dir if address is empty, otherwise on loopback tcp address.
+func listen(address string) (net.Listener, error) {
+	const location = "admin.listen->"
+
+	if address == "" {
+		socket := paths.List().AdminSocket
+
+		_, err := os.Stat(socket)
+		if err == nil {
+			conn, err := net.DialTimeout("unix", socket, time.Second)
+			if err == nil {
+				conn.Close()
+				return nil, logger.MarkLocation(location, ErrSocketInUse)
+			}
+
+			// socket is left by node that was not stopped properly
+			err = os.Remove(socket)
+			if err != nil {
+				return nil, logger.MarkLocation(location, err)
+			}
+		}
+
+		lis, err := net.Listen("unix", socket)
+		if err != nil {
+			return nil, logger.MarkLocation(location, err)
+		}
+
+		err = os.Chmod(socket, 0600)
+		if err != nil {
+			lis.Close()
+			return nil, logger.MarkLocation(location, err)
+		}
+
+		return lis, nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	ip := net.ParseIP(host)
+
+	if host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, logger.MarkLocation(location, fmt.Errorf("%s: %w", address, ErrNotLoopback))
+	}
+
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	return lis, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Handler serves /status, /storage/usage, /proofs/pause, /proofs/resume, /cleaner/run, /cleaner/restore and /check.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/status", method(http.MethodGet, serveStatus))
+	mux.HandleFunc("/storage/usage", method(http.MethodGet, serveStorageUsage))
+	mux.HandleFunc("/proofs/pause", method(http.MethodPost, servePauseProofs))
+	mux.HandleFunc("/proofs/resume", method(http.MethodPost, serveResumeProofs))
+	mux.HandleFunc("/cleaner/run", method(http.MethodPost, serveRunCleaner))
+	mux.HandleFunc("/cleaner/restore", method(http.MethodPost, serveRestoreQuarantined))
+	mux.HandleFunc("/check", method(http.MethodGet, serveCheck))
+
+	return mux
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func method(name string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != name {
+			w.Header().Set("Allow", name)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func currentStatus() (Status, error) {
+	const location = "admin.currentStatus->"
+
+	status, err := health.Current()
+	if err != nil {
+		return Status{}, logger.MarkLocation(location, err)
+	}
+
+	return Status{Status: status, ProofsPaused: blckChain.ProofsPaused()}, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func serveStatus(w http.ResponseWriter, r *http.Request) {
+	const location = "admin.serveStatus->"
+
+	status, err := currentStatus()
+	if err != nil {
+		writeError(w, logger.MarkLocation(location, err))
+		return
+	}
+
+	writeJSON(w, status)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func serveStorageUsage(w http.ResponseWriter, r *http.Request) {
+	const location = "admin.serveStorageUsage->"
+
+	status, err := health.Current()
+	if err != nil {
+		writeError(w, logger.MarkLocation(location, err))
+		return
+	}
+
+	usage := StorageUsage{
+		Limit:    status.StorageLimit,
+		Reserved: status.StorageReserved,
+		Free:     status.FreeSpace,
+		Networks: map[string]NetworkUsage{},
+	}
+
+	for _, network := range networks.List() {
+		sps, err := quota.StorageProviders(network)
+		if err != nil {
+			writeError(w, logger.MarkLocation(location, err))
+			return
+		}
+
+		netUsage := NetworkUsage{StorageProviders: sps}
+
+		for _, used := range sps {
+			netUsage.Used += used
+		}
+
+		usage.Networks[network] = netUsage
+	}
+
+	writeJSON(w, usage)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func servePauseProofs(w http.ResponseWriter, r *http.Request) {
+	blckChain.PauseProofs()
+	logger.Info("proofs are paused", nil)
+
+	serveStatus(w, r)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func serveResumeProofs(w http.ResponseWriter, r *http.Request) {
+	blckChain.ResumeProofs()
+	logger.Info("proofs are resumed", nil)
+
+	serveStatus(w, r)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func serveRunCleaner(w http.ResponseWriter, r *http.Request) {
+	const location = "admin.serveRunCleaner->"
+
+	report, err := cleaner.Run(false)
+	if err != nil {
+		writeError(w, logger.MarkLocation(location, err))
+		return
+	}
+
+	writeJSON(w, report)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// serveRestoreQuarantined restores parts passed in "part" query params, all quarantined parts are restored if none is passed.
+func serveRestoreQuarantined(w http.ResponseWriter, r *http.Request) {
+	const location = "admin.serveRestoreQuarantined->"
+
+	restored, err := cleaner.Restore(r.URL.Query()["part"])
+	if err != nil {
+		writeError(w, logger.MarkLocation(location, err))
+		return
+	}
+
+	writeJSON(w, restored)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// serveCheck checks reachability of the node by the address in smart contract, it's done by the running node
+// because it has the account unlocked and its nonces can be told from nonces of another node.
+func serveCheck(w http.ResponseWriter, r *http.Request) {
+	const location = "admin.serveCheck->"
+
+	nodeConfig := reload.Current()
+
+	report, err := probe.New(common.HexToAddress(nodeConfig.Address), nodeConfig.IPWatcher).Check(r.Context(), nodeConfig)
+	if err != nil {
+		writeError(w, logger.MarkLocation(location, err))
+		return
+	}
+
+	writeJSON(w, report)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	const location = "admin.writeJSON->"
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// writeError sends error message to admin, it's a local user so error is not hidden.
+func writeError(w http.ResponseWriter, err error) {
+	logger.Log(err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,89 @@
+package admin_test
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/admin"
+	"github.com/DeNetPRO/src/config"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	tstpkg.TestModeOn()
+	defer tstpkg.TestModeOff()
+
+	err := paths.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = config.Create(tstpkg.Data().AccAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	exitVal := m.Run()
+
+	err = os.RemoveAll(paths.List().WorkDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitVal)
+}
+
+func TestAdmin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- admin.Serve(ctx, nodeTypes.AdminConfig{})
+	}()
+
+	client := admin.NewClient("")
+
+	require.Eventually(t, func() bool {
+		_, err := client.Status(context.Background())
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	status, err := client.Status(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, tstpkg.Data().AccAddr, status.Account)
+	require.False(t, status.ProofsPaused)
+
+	status, err = client.PauseProofs(context.Background())
+	require.NoError(t, err)
+	require.True(t, status.ProofsPaused)
+
+	status, err = client.ResumeProofs(context.Background())
+	require.NoError(t, err)
+	require.False(t, status.ProofsPaused)
+
+	usage, err := client.StorageUsage(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, status.StorageLimit, usage.Limit)
+
+	restored, err := client.RestoreQuarantined(context.Background(), []string{"part"})
+	require.NoError(t, err)
+	require.Empty(t, restored)
+
+	cancel()
+	require.NoError(t, <-served)
+
+	_, err = client.Status(context.Background())
+	require.ErrorIs(t, err, admin.ErrNotRunning)
+}
+
+func TestNotLoopback(t *testing.T) {
+	err := admin.Serve(context.Background(), nodeTypes.AdminConfig{Address: "0.0.0.0:9478"})
+	require.ErrorIs(t, err, admin.ErrNotLoopback)
+}
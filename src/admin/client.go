@@ -0,0 +1,187 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/probe"
+)
+
+var ErrNotRunning = errors.New("node is not running or admin api is disabled")
+
+// Client sends requests to admin api of the running node.
+type Client struct {
+	httpClient *http.Client
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// NewClient makes client for admin api served on address, empty address means unix socket in work dir.
+func NewClient(address string) *Client {
+	network := "tcp"
+
+	if address == "" {
+		network = "unix"
+		address = paths.List().AdminSocket
+	}
+
+	dialer := &net.Dialer{}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+
+	return &Client{httpClient: &http.Client{Transport: transport}}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	const location = "admin.Client.Status->"
+
+	var status Status
+
+	err := c.do(ctx, http.MethodGet, "/status", &status)
+	if err != nil {
+		return status, logger.MarkLocation(location, err)
+	}
+
+	return status, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c *Client) StorageUsage(ctx context.Context) (StorageUsage, error) {
+	const location = "admin.Client.StorageUsage->"
+
+	var usage StorageUsage
+
+	err := c.do(ctx, http.MethodGet, "/storage/usage", &usage)
+	if err != nil {
+		return usage, logger.MarkLocation(location, err)
+	}
+
+	return usage, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c *Client) PauseProofs(ctx context.Context) (Status, error) {
+	const location = "admin.Client.PauseProofs->"
+
+	var status Status
+
+	err := c.do(ctx, http.MethodPost, "/proofs/pause", &status)
+	if err != nil {
+		return status, logger.MarkLocation(location, err)
+	}
+
+	return status, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c *Client) ResumeProofs(ctx context.Context) (Status, error) {
+	const location = "admin.Client.ResumeProofs->"
+
+	var status Status
+
+	err := c.do(ctx, http.MethodPost, "/proofs/resume", &status)
+	if err != nil {
+		return status, logger.MarkLocation(location, err)
+	}
+
+	return status, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// RunCleaner makes cleaner pass in the running node and returns its report.
+func (c *Client) RunCleaner(ctx context.Context) (nodeTypes.CleanerReport, error) {
+	const location = "admin.Client.RunCleaner->"
+
+	var report nodeTypes.CleanerReport
+
+	err := c.do(ctx, http.MethodPost, "/cleaner/run", &report)
+	if err != nil {
+		return report, logger.MarkLocation(location, err)
+	}
+
+	return report, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// RestoreQuarantined moves quarantined parts back to storage in the running node, so cleaner state
+// isn't changed by two processes at once. All quarantined parts are restored if no part names are passed.
+func (c *Client) RestoreQuarantined(ctx context.Context, fileNames []string) ([]string, error) {
+	const location = "admin.Client.RestoreQuarantined->"
+
+	var restored []string
+
+	query := url.Values{"part": fileNames}
+
+	err := c.do(ctx, http.MethodPost, "/cleaner/restore?"+query.Encode(), &restored)
+	if err != nil {
+		return restored, logger.MarkLocation(location, err)
+	}
+
+	return restored, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c *Client) do(ctx context.Context, method, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, "http://admin"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return ErrNotRunning
+		}
+
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("admin api: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Check makes the running node check its reachability by the address in smart contract.
+func (c *Client) Check(ctx context.Context) (probe.Report, error) {
+	const location = "admin.Client.Check->"
+
+	var report probe.Report
+
+	err := c.do(ctx, http.MethodGet, "/check", &report)
+	if err != nil {
+		return report, logger.MarkLocation(location, err)
+	}
+
+	return report, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
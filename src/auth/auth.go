@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"container/list"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/pb"
+	"github.com/DeNetPRO/src/sign"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	nonceSize     = 32
+	nonceLifetime = time.Minute * 5
+	maxClockSkew  = time.Minute * 5
+	maxNonces     = 100000
+
+	// new nonces are refused to requester that has that many unused nonces
+	maxRequesterNonces = 64
+)
+
+var mutex sync.Mutex
+
+type issuedNonce struct {
+	key       string
+	signer    common.Address
+	requester string
+	expiresAt time.Time
+}
+
+// nonces keeps issued nonces that weren't used yet, nonce is removed once a request signed with it is verified.
+// Nonces are listed in issue order, which is expiration order too. Unused nonces are counted by requester,
+// so one client can't fill the table, and a full table refuses new nonces instead of dropping issued ones.
+var (
+	nonces          = map[string]*list.Element{}
+	issueOrder      = list.New()
+	requesterNonces = map[string]int{}
+)
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// NewNonce issues one-time nonce for the signer and returns it with expiration unix timestamp.
+// Requester is the remote address that asks for nonce, it can't hold more than maxRequesterNonces unused nonces.
+func NewNonce(signer []byte, requester string) ([]byte, int64, error) {
+	const location = "auth.NewNonce->"
+
+	if len(signer) != common.AddressLength {
+		return nil, 0, logger.MarkLocation(location, errs.List().Argument)
+	}
+
+	nonce := make([]byte, nonceSize)
+
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, 0, logger.MarkLocation(location, err)
+	}
+
+	expiresAt := time.Now().Add(nonceLifetime)
+
+	signerAddr := common.BytesToAddress(signer)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	removeExpired()
+
+	if requesterNonces[requester] >= maxRequesterNonces || len(nonces) >= maxNonces {
+		return nil, 0, logger.MarkLocation(location, errs.List().RateLimit)
+	}
+
+	key := hex.EncodeToString(nonce)
+
+	nonces[key] = issueOrder.PushBack(&issuedNonce{key: key, signer: signerAddr, requester: requester, expiresAt: expiresAt})
+	requesterNonces[requester]++
+
+	return nonce, expiresAt.Unix(), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Digest returns hash that is signed by requester: sha256(method + network + payload hash + nonce + timestamp).
+func Digest(method, network string, payloadHash [32]byte, nonce []byte, timestamp int64) [32]byte {
+	timestampBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(timestampBytes, uint64(timestamp))
+
+	data := make([]byte, 0, len(method)+len(network)+len(payloadHash)+len(nonce)+len(timestampBytes))
+	data = append(data, method...)
+	data = append(data, network...)
+	data = append(data, payloadHash[:]...)
+	data = append(data, nonce...)
+	data = append(data, timestampBytes...)
+
+	return sha256.Sum256(data)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Verify checks request signature and spends its nonce, so the same request can't be replayed. Returns address of the signer.
+// Nonce is spent only by a valid signature, so forged requests can't spend nonces of other signers.
+func Verify(signature *pb.Signature, method, network string, payloadHash [32]byte) (common.Address, error) {
+	const location = "auth.Verify->"
+
+	if signature == nil || len(signature.Signer) != common.AddressLength {
+		return common.Address{}, logger.MarkLocation(location, errs.List().Signature)
+	}
+
+	signer := common.BytesToAddress(signature.Signer)
+
+	signedAt := time.Unix(signature.Timestamp, 0)
+
+	if time.Since(signedAt) > maxClockSkew || time.Until(signedAt) > maxClockSkew {
+		return signer, logger.MarkLocation(location, errs.List().Nonce)
+	}
+
+	nonceKey := hex.EncodeToString(signature.Nonce)
+
+	mutex.Lock()
+	issued, found := lookup(nonceKey)
+	mutex.Unlock()
+
+	if !found || issued.signer != signer || time.Now().After(issued.expiresAt) {
+		return signer, logger.MarkLocation(location, errs.List().Nonce)
+	}
+
+	digest := Digest(method, network, payloadHash, signature.Nonce, signature.Timestamp)
+
+	err := sign.Check(signer.String(), hex.EncodeToString(signature.SignedBytes), digest)
+	if err != nil {
+		return signer, logger.MarkLocation(location, errs.List().Signature)
+	}
+
+	mutex.Lock()
+	spent := remove(nonceKey)
+	mutex.Unlock()
+
+	// concurrent request with the same nonce was verified first
+	if !spent {
+		return signer, logger.MarkLocation(location, errs.List().Nonce)
+	}
+
+	return signer, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Issued reports if nonce was issued to the signer by this node and wasn't used yet.
+func Issued(nonce []byte, signer common.Address) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	issued, found := lookup(hex.EncodeToString(nonce))
+
+	return found && issued.signer == signer && time.Now().Before(issued.expiresAt)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// lookup must be called with mutex locked.
+func lookup(key string) (issuedNonce, bool) {
+	elem, found := nonces[key]
+	if !found {
+		return issuedNonce{}, false
+	}
+
+	return *elem.Value.(*issuedNonce), true
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// remove must be called with mutex locked. Returns false if nonce was already removed.
+func remove(key string) bool {
+	elem, found := nonces[key]
+	if !found {
+		return false
+	}
+
+	issued := elem.Value.(*issuedNonce)
+
+	issueOrder.Remove(elem)
+	delete(nonces, key)
+
+	requesterNonces[issued.requester]--
+	if requesterNonces[issued.requester] == 0 {
+		delete(requesterNonces, issued.requester)
+	}
+
+	return true
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// removeExpired must be called with mutex locked.
+func removeExpired() {
+	now := time.Now()
+
+	for elem := issueOrder.Front(); elem != nil; elem = issueOrder.Front() {
+		issued := elem.Value.(*issuedNonce)
+		if !now.After(issued.expiresAt) {
+			return
+		}
+
+		remove(issued.key)
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,111 @@
+package auth_test
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/auth"
+	"github.com/DeNetPRO/src/encryption"
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/pb"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func signRequest(t *testing.T, method string, payloadHash [32]byte) *pb.Signature {
+	privateKeyBytes, err := encryption.DecryptAES(tstpkg.Data().EncrKey, tstpkg.Data().PKHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey, err := crypto.ToECDSA(privateKeyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := common.HexToAddress(tstpkg.Data().AccAddr)
+
+	nonce, _, err := auth.NewNonce(signer.Bytes(), "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Now().Unix()
+
+	digest := auth.Digest(method, "kovan", payloadHash, nonce, timestamp)
+
+	signedBytes, err := crypto.Sign(digest[:], privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &pb.Signature{Signer: signer.Bytes(), Nonce: nonce, SignedBytes: signedBytes, Timestamp: timestamp}
+}
+
+func TestVerify(t *testing.T) {
+	payloadHash := sha256.Sum256([]byte(tstpkg.Data().AccAddr))
+
+	signature := signRequest(t, "DownloadFS", payloadHash)
+
+	signer, err := auth.Verify(signature, "DownloadFS", "kovan", payloadHash)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress(tstpkg.Data().AccAddr), signer)
+
+	_, err = auth.Verify(signature, "DownloadFS", "kovan", payloadHash)
+	require.ErrorIs(t, err, errs.List().Nonce)
+
+	signature = signRequest(t, "DownloadFS", payloadHash)
+
+	_, err = auth.Verify(signature, "UploadFS", "kovan", payloadHash)
+	require.ErrorIs(t, err, errs.List().Signature)
+
+	signature = signRequest(t, "DownloadFS", payloadHash)
+	signature.Timestamp -= 60 * 60
+
+	_, err = auth.Verify(signature, "DownloadFS", "kovan", payloadHash)
+	require.ErrorIs(t, err, errs.List().Nonce)
+
+	_, _, err = auth.NewNonce([]byte("short"), "127.0.0.1")
+	require.ErrorIs(t, err, errs.List().Argument)
+}
+
+func TestIssued(t *testing.T) {
+	signer := common.HexToAddress("0x1")
+
+	nonce, _, err := auth.NewNonce(signer.Bytes(), "10.0.0.1")
+	require.NoError(t, err)
+
+	require.True(t, auth.Issued(nonce, signer))
+	require.False(t, auth.Issued(nonce, common.HexToAddress("0x2")))
+
+	// forged request doesn't spend signer's nonce
+	_, err = auth.Verify(&pb.Signature{Signer: signer.Bytes(), Nonce: nonce, Timestamp: time.Now().Unix()}, "GetNonce", "kovan", sha256.Sum256(nil))
+	require.ErrorIs(t, err, errs.List().Signature)
+
+	require.True(t, auth.Issued(nonce, signer))
+}
+
+func TestRequesterNoncesLimit(t *testing.T) {
+	signer := common.HexToAddress("0x3")
+
+	first, _, err := auth.NewNonce(signer.Bytes(), "10.0.0.2")
+	require.NoError(t, err)
+
+	for i := 1; i < 64; i++ {
+		_, _, err = auth.NewNonce(common.HexToAddress("0x4").Bytes(), "10.0.0.2")
+		require.NoError(t, err)
+	}
+
+	_, _, err = auth.NewNonce(signer.Bytes(), "10.0.0.2")
+	require.ErrorIs(t, err, errs.List().RateLimit)
+
+	// issued nonces are kept and other requesters still get new ones
+	require.True(t, auth.Issued(first, signer))
+
+	other, _, err := auth.NewNonce(signer.Bytes(), "10.0.0.3")
+	require.NoError(t, err)
+	require.True(t, auth.Issued(other, signer))
+}
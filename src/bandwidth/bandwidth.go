@@ -0,0 +1,233 @@
+package bandwidth
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+)
+
+type Direction int
+
+const (
+	Upload Direction = iota
+	Download
+)
+
+const cleanupInterval = time.Minute
+
+// Stat throughput is set in bytes per second, limits are the ones applied now.
+type Stat struct {
+	Upload               float64
+	Download             float64
+	GatewayDownload      float64
+	UploadLimit          int64
+	DownloadLimit        int64
+	GatewayDownloadLimit int64
+}
+
+// bucket allows rate bytes per second with one second burst. Tokens may go negative,
+// chunk that takes more than available tokens waits until the debt is paid off.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type spBuckets struct {
+	upload   bucket
+	download bucket
+}
+
+var (
+	mutex           sync.Mutex
+	bandwidthConfig nodeTypes.BandwidthConfig
+	uploadBucket    bucket
+	downloadBucket  bucket
+	gatewayBucket   bucket
+	sps             = map[string]*spBuckets{}
+	lastCleanup     = time.Now()
+
+	uploadMeter   = newMeter()
+	downloadMeter = newMeter()
+	gatewayMeter  = newMeter()
+)
+
+// SetConfig sets bandwidth limits and schedules.
+func SetConfig(conf nodeTypes.BandwidthConfig) {
+	mutex.Lock()
+	bandwidthConfig = conf
+	mutex.Unlock()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Wait blocks until size bytes can be transferred within global, storage provider's and, for gateway downloads,
+// gateway limits. Upload limits are applied to received data, download limits to sent data.
+func Wait(ctx context.Context, direction Direction, spAddress string, gateway bool, size int) error {
+	now := time.Now()
+
+	spAddress = strings.ToLower(spAddress)
+
+	mutex.Lock()
+
+	limits := currentLimits(now)
+
+	if now.Sub(lastCleanup) >= cleanupInterval {
+		cleanup(limits, now)
+	}
+
+	sp, ok := sps[spAddress]
+	if !ok {
+		sp = &spBuckets{}
+		sps[spAddress] = sp
+	}
+
+	var delay time.Duration
+
+	if direction == Upload {
+		delay = maxDuration(
+			uploadBucket.take(limits.Upload, size, now),
+			sp.upload.take(limits.SpUpload, size, now),
+		)
+	} else {
+		delay = maxDuration(
+			downloadBucket.take(limits.Download, size, now),
+			sp.download.take(limits.SpDownload, size, now),
+		)
+
+		if gateway {
+			delay = maxDuration(delay, gatewayBucket.take(limits.GatewayDownload, size, now))
+		}
+	}
+
+	mutex.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if direction == Upload {
+		uploadMeter.add(size)
+	} else {
+		downloadMeter.add(size)
+
+		if gateway {
+			gatewayMeter.add(size)
+		}
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// CurrentStat returns throughput averaged over the last seconds and limits applied now.
+func CurrentStat() Stat {
+	mutex.Lock()
+	limits := currentLimits(time.Now())
+	mutex.Unlock()
+
+	return Stat{
+		Upload:               uploadMeter.rate(),
+		Download:             downloadMeter.rate(),
+		GatewayDownload:      gatewayMeter.rate(),
+		UploadLimit:          limits.Upload,
+		DownloadLimit:        limits.Download,
+		GatewayDownloadLimit: limits.GatewayDownload,
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// currentLimits returns limits of the first active schedule or default limits.
+// Must be called with mutex locked.
+func currentLimits(now time.Time) nodeTypes.BandwidthLimits {
+	hour := now.Hour()
+
+	for _, schedule := range bandwidthConfig.Schedules {
+		if schedule.From <= schedule.To && hour >= schedule.From && hour < schedule.To {
+			return schedule.Limits
+		}
+
+		if schedule.From > schedule.To && (hour >= schedule.From || hour < schedule.To) {
+			return schedule.Limits
+		}
+	}
+
+	return bandwidthConfig.Limits
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// take takes size tokens and returns time to wait until bucket has no debt. Zero rate means no limit.
+func (b *bucket) take(rate int64, size int, now time.Time) time.Duration {
+	if rate <= 0 {
+		// bucket starts full when limit is set again
+		b.updated = time.Time{}
+		return 0
+	}
+
+	if b.updated.IsZero() {
+		b.tokens = float64(rate)
+	} else {
+		b.tokens += now.Sub(b.updated).Seconds() * float64(rate)
+	}
+
+	if b.tokens > float64(rate) {
+		b.tokens = float64(rate)
+	}
+
+	b.updated = now
+	b.tokens -= float64(size)
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / float64(rate) * float64(time.Second))
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// cleanup removes buckets of storage providers that have no debt and are full again.
+// Must be called with mutex locked.
+func cleanup(limits nodeTypes.BandwidthLimits, now time.Time) {
+	for spAddress, sp := range sps {
+		if sp.upload.full(limits.SpUpload, now) && sp.download.full(limits.SpDownload, now) {
+			delete(sps, spAddress)
+		}
+	}
+
+	lastCleanup = now
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (b *bucket) full(rate int64, now time.Time) bool {
+	if rate <= 0 || b.updated.IsZero() {
+		return true
+	}
+
+	return b.tokens+now.Sub(b.updated).Seconds()*float64(rate) >= float64(rate)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
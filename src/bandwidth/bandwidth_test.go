@@ -0,0 +1,64 @@
+package bandwidth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/bandwidth"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/stretchr/testify/require"
+)
+
+const spAddress = "0x0000000000000000000000000000000000000001"
+
+func TestWait(t *testing.T) {
+	bandwidth.SetConfig(nodeTypes.BandwidthConfig{
+		Limits: nodeTypes.BandwidthLimits{SpDownload: 1000},
+	})
+
+	statBefore := bandwidth.CurrentStat()
+
+	start := time.Now()
+
+	err := bandwidth.Wait(context.Background(), bandwidth.Download, spAddress, false, 1000)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+
+	err = bandwidth.Wait(context.Background(), bandwidth.Download, spAddress, false, 300)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+
+	// uploads and other storage providers aren't limited
+	start = time.Now()
+
+	err = bandwidth.Wait(context.Background(), bandwidth.Upload, spAddress, false, 10000)
+	require.NoError(t, err)
+
+	err = bandwidth.Wait(context.Background(), bandwidth.Download, "0x0000000000000000000000000000000000000002", false, 1000)
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+
+	stat := bandwidth.CurrentStat()
+	require.Equal(t, float64(1000), stat.Upload-statBefore.Upload)
+	require.Equal(t, float64(230), stat.Download-statBefore.Download)
+}
+
+func TestSchedule(t *testing.T) {
+	bandwidth.SetConfig(nodeTypes.BandwidthConfig{
+		Schedules: []nodeTypes.BandwidthSchedule{
+			{From: 0, To: 24, Limits: nodeTypes.BandwidthLimits{GatewayDownload: 1000}},
+		},
+	})
+
+	require.Equal(t, int64(1000), bandwidth.CurrentStat().GatewayDownloadLimit)
+
+	err := bandwidth.Wait(context.Background(), bandwidth.Download, spAddress, true, 1000)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err = bandwidth.Wait(ctx, bandwidth.Download, spAddress, true, 500)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
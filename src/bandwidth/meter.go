@@ -0,0 +1,58 @@
+package bandwidth
+
+import (
+	"sync"
+	"time"
+)
+
+const meterWindow = 10 // seconds
+
+// meter counts transferred bytes for each of the last seconds.
+type meter struct {
+	mutex   sync.Mutex
+	slots   [meterWindow]int64
+	seconds [meterWindow]int64
+}
+
+func newMeter() *meter {
+	return &meter{}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (m *meter) add(size int) {
+	second := time.Now().Unix()
+	i := second % meterWindow
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.seconds[i] != second {
+		m.seconds[i] = second
+		m.slots[i] = 0
+	}
+
+	m.slots[i] += int64(size)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// rate returns bytes per second averaged over the window.
+func (m *meter) rate() float64 {
+	now := time.Now().Unix()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var total int64
+
+	for i := range m.slots {
+		if now-m.seconds[i] < meterWindow {
+			total += m.slots[i]
+		}
+	}
+
+	return float64(total) / meterWindow
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
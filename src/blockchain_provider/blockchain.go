@@ -4,16 +4,15 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"log"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/minio/sha256-simd"
 
 	"encoding/binary"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
 	"os"
@@ -22,11 +21,15 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/DeNetPRO/src/cleaner"
 	"github.com/DeNetPRO/src/config"
 	"github.com/DeNetPRO/src/encryption"
 	erc20 "github.com/DeNetPRO/src/erc20"
+	fsysInfo "github.com/DeNetPRO/src/fsys_info"
 	"github.com/DeNetPRO/src/hash"
+	"github.com/DeNetPRO/src/health"
 	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/metrics"
 	"github.com/DeNetPRO/src/networks"
 
 	nodeFile "github.com/DeNetPRO/src/node_file"
@@ -44,13 +47,26 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-const eightKB = 8192
+const (
+	eightKB = 8192
+
+	// proof worker must check storage providers at least this often to be considered alive
+	proofsBeatTimeout = 15 * time.Minute
+
+	// pending proof transactions are tracked for that long after proofs worker stops, it's less than supervisor's shutdown timeout
+	trackersDrainTimeout = time.Minute
+)
 
 var (
 	mutex     sync.Mutex
 	proofOpts *bind.TransactOpts
+
+	proofsPaused int32
 )
 
+// ErrLowBalance is returned when node can't pay transaction fees.
+var ErrLowBalance = errors.New("not sufficient funds for transactions")
+
 // RegisterNode registers a node in the ethereum network.
 // Node's balance should have more than 200000000000000 wei to pay transaction comission.
 func RegisterNode(ctx context.Context, nodeAddr common.Address, password string, nodeConfig nodeTypes.Config) error {
@@ -88,10 +104,13 @@ func RegisterNode(ctx context.Context, nodeAddr common.Address, password string,
 		return logger.MarkLocation(location, err)
 	}
 
-	_, err = checkBalance(client, nodeAddr, blockNum, true)
+	balanceIsLow, err := checkBalance(client, nodeAddr, blockNum)
 	if err != nil {
-		logger.Log(logger.MarkLocation(location, err))
-		log.Fatal("couldn't check balance")
+		return logger.MarkLocation(location, err)
+	}
+
+	if balanceIsLow {
+		return logger.MarkLocation(location, ErrLowBalance)
 	}
 
 	nodeNft, err := nodeNftAbi.NewNodeNft(common.HexToAddress(networks.Fields().NODE), client)
@@ -174,62 +193,129 @@ func UpdateNodeInfo(ctx context.Context, nodeAddr common.Address, password, newI
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
+// NodeInfo returns ip address and port that are set for node in smart contract of the current network.
+func NodeInfo(ctx context.Context, nodeAddr common.Address) (string, string, error) {
+	const location = "blckChain.NodeInfo->"
+
+	client, err := ethclient.DialContext(ctx, config.RPC)
+	if err != nil {
+		return "", "", logger.MarkLocation(location, err)
+	}
+
+	defer client.Close()
+
+	nodeNft, err := nodeNftAbi.NewNodeNft(common.HexToAddress(networks.Fields().NODE), client)
+	if err != nil {
+		return "", "", logger.MarkLocation(location, err)
+	}
+
+	nodeID, err := nodeNft.GetNodeIDByAddress(&bind.CallOpts{Context: ctx}, nodeAddr)
+	if err != nil {
+		return "", "", logger.MarkLocation(location, err)
+	}
+
+	info, err := nodeNft.NodeInfo(&bind.CallOpts{Context: ctx}, nodeID)
+	if err != nil {
+		return "", "", logger.MarkLocation(location, err)
+	}
+
+	ip := fmt.Sprintf("%d.%d.%d.%d", info.IpAddress[0], info.IpAddress[1], info.IpAddress[2], info.IpAddress[3])
+
+	return ip, strconv.Itoa(int(info.Port)), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// IsRegisteredNode checks if address owns a node registered in the current network.
+func IsRegisteredNode(ctx context.Context, address common.Address) (bool, error) {
+	const location = "blckChain.IsRegisteredNode->"
+
+	client, err := ethclient.DialContext(ctx, config.RPC)
+	if err != nil {
+		return false, logger.MarkLocation(location, err)
+	}
+
+	defer client.Close()
+
+	nodeNft, err := nodeNftAbi.NewNodeNft(common.HexToAddress(networks.Fields().NODE), client)
+	if err != nil {
+		return false, logger.MarkLocation(location, err)
+	}
+
+	nodesCount, err := nodeNft.BalanceOf(&bind.CallOpts{Context: ctx}, address)
+	if err != nil {
+		return false, logger.MarkLocation(location, err)
+	}
+
+	return nodesCount.Sign() > 0, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
 // StartMakingProofs checks reward value for stored file part and sends proof to smart contract if reward is enough.
-func StartMakingProofs(nodeAddr common.Address, password string, nodeConfig nodeTypes.Config) {
+// Proofs are made until ctx is done, then pending transactions are tracked until they are mined or drain timeout passes.
+func StartMakingProofs(ctx context.Context, nodeAddr common.Address, password string, nodeConfig nodeTypes.Config) error {
 	const location = "blckChain.StartMakingProofs->"
 
 	regAddr := regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
 	regFileName := regexp.MustCompile("[0-9A-Za-z_]")
 
+	health.Register(health.Proofs, proofsBeatTimeout)
+
 	client, err := ethclient.Dial(nodeConfig.RPC[nodeConfig.Network])
 	if err != nil {
-		logger.Log(logger.MarkLocation(location, err))
-		log.Fatal("couldn't set up a new network client")
+		return logger.MarkLocation(location, err)
 	}
 	defer client.Close()
 
+	tracker := NewTxTracker()
+
+	defer func() {
+		if !tracker.Drain(trackersDrainTimeout) {
+			logger.Warn("pending proof transactions are not tracked anymore", logger.Fields{"network": networks.Current()})
+		}
+	}()
+
 	posInstance, err := PoS.NewPos(common.HexToAddress(networks.Fields().PoS), client)
 	if err != nil {
-		logger.Log(logger.MarkLocation(location, err))
-		log.Fatal("couldn't set up new proof of storage instance")
+		return logger.MarkLocation(location, err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	initCtx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
 
-	blockNum, err := client.BlockNumber(ctx)
+	blockNum, err := client.BlockNumber(initCtx)
 	if err != nil {
-		cancel()
-		logger.Log(logger.MarkLocation(location, err))
+		health.SetEndpointError(err)
+		return logger.MarkLocation(location, err)
 	}
 
-	_, err = checkBalance(client, nodeAddr, blockNum, true)
+	health.SetLastBlock(blockNum)
+
+	balanceIsLow, err := checkBalance(client, nodeAddr, blockNum)
 	if err != nil {
-		cancel()
-		logger.Log(logger.MarkLocation(location, err))
-		log.Fatal("couldn't check balance")
+		return logger.MarkLocation(location, err)
+	}
+
+	if balanceIsLow {
+		return logger.MarkLocation(location, ErrLowBalance)
 	}
 
-	baseDiff, err := posInstance.BaseDifficulty(&bind.CallOpts{BlockNumber: big.NewInt(int64(blockNum))})
+	baseDiff, err := posInstance.BaseDifficulty(&bind.CallOpts{Context: initCtx, BlockNumber: big.NewInt(int64(blockNum))})
 	if err != nil {
-		cancel()
-		logger.Log(logger.MarkLocation(location, err))
-		log.Fatal("couldn't get base difficulty")
+		return logger.MarkLocation(location, err)
 	}
 
-	proofOpts, err = initTrxOpts(ctx, client, nodeAddr, password, blockNum)
+	proofOpts, err = initTrxOpts(initCtx, client, nodeAddr, password, blockNum)
 	if err != nil {
-		cancel()
-		logger.Log(logger.MarkLocation(location, err))
-		log.Fatal("couldn't initialize transaction options")
+		return logger.MarkLocation(location, err)
 	}
 
 	debug.FreeOSMemory()
 
-	transactNonce, err := client.NonceAt(ctx, nodeAddr, big.NewInt(int64(blockNum)))
+	transactNonce, err := client.NonceAt(initCtx, nodeAddr, big.NewInt(int64(blockNum)))
 	if err != nil {
-		cancel()
-		logger.Log(logger.MarkLocation(location, err))
-		log.Fatal("couldn't get transaction nonce")
+		return logger.MarkLocation(location, err)
 	}
 
 	cancel()
@@ -242,17 +328,28 @@ func StartMakingProofs(nodeAddr common.Address, password string, nodeConfig node
 
 	for {
 
-		time.Sleep(time.Second * 20)
+		if !sleep(ctx, time.Second*20) {
+			return nil
+		}
+
+		health.Beat(health.Proofs)
+
+		if ProofsPaused() {
+			continue
+		}
 
 		stat, err := os.Stat(pathToAccStorage)
 		if err != nil && !errors.Is(err, os.ErrNotExist) {
-			logger.Log(logger.MarkLocation(location, err))
-			log.Fatal(err)
+			return logger.MarkLocation(location, err)
 		}
 
 		if stat == nil {
 			fmt.Println("no files from", networks.Current(), "to proof")
-			time.Sleep(time.Minute * 1)
+
+			if !sleep(ctx, time.Minute*1) {
+				return nil
+			}
+
 			continue
 		}
 
@@ -280,25 +377,24 @@ func StartMakingProofs(nodeAddr common.Address, password string, nodeConfig node
 
 		for _, spAddress := range storageProviderAddresses {
 
-			time.Sleep(time.Second * 10)
+			if !sleep(ctx, time.Second*10) {
+				return nil
+			}
 
-			pathToFsTree := filepath.Join(paths.List().Storages[0], networks.Current(), spAddress, paths.List().SpFsFilename)
+			health.Beat(health.Proofs)
 
-			mutex.Lock()
+			if ProofsPaused() {
+				break
+			}
 
-			spFsFile, spFsBytes, err := nodeFile.Read(pathToFsTree)
+			contractRootHash, contractNonce, err := posInstance.GetUserRootHash(&bind.CallOpts{}, common.HexToAddress(spAddress))
 			if err != nil {
-				mutex.Unlock()
 				logger.Log(logger.MarkLocation(location, err))
 				continue
 			}
 
-			spFsFile.Close()
-			mutex.Unlock()
-
-			var spFs nodeTypes.StorageProviderData
-
-			err = json.Unmarshal(spFsBytes, &spFs)
+			// reward is checked for the snapshot that is proven, its tree is loaded only when proof is sent
+			spFs, err := selectFsSnapshot(spAddress, contractRootHash, contractNonce)
 			if err != nil {
 				logger.Log(logger.MarkLocation(location, err))
 				continue
@@ -322,6 +418,8 @@ func StartMakingProofs(nodeAddr common.Address, password string, nodeConfig node
 				continue
 			}
 
+			cleaner.ReportBalance(networks.Current(), spAddress, weiBalance.Sign() > 0)
+
 			rewardToGbY := float64(reward.Int64()) / 1000000000000000000 * 1000
 
 			if rewardToGbY < 0.3 {
@@ -334,9 +432,6 @@ func StartMakingProofs(nodeAddr common.Address, password string, nodeConfig node
 
 			if !balanceIsEnough {
 				fmt.Println(spAddress, "balance is not enough")
-				// if weiBalance.Cmp(big.NewInt(0)) == 0 {
-				// cleaner.MarkUnused(spAddress)
-				// }
 				continue
 			}
 
@@ -364,17 +459,20 @@ func StartMakingProofs(nodeAddr common.Address, password string, nodeConfig node
 				continue
 			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+			blockCtx, cancel := context.WithTimeout(ctx, time.Second*30)
 
-			blockNum, err = client.BlockNumber(ctx)
+			blockNum, err = client.BlockNumber(blockCtx)
 			if err != nil {
 				cancel()
+				health.SetEndpointError(err)
 				logger.Log(logger.MarkLocation(location, err))
 				continue
 			}
 
 			cancel()
 
+			health.SetLastBlock(blockNum)
+
 			blockHash, err := posInstance.GetBlockHash(&bind.CallOpts{}, uint32(blockNum-10)) // checking older blocknum to guarantee valid result
 			if err != nil {
 				logger.Log(logger.MarkLocation(location, err))
@@ -423,9 +521,11 @@ func StartMakingProofs(nodeAddr common.Address, password string, nodeConfig node
 
 				fmt.Println("Trying proof", fileName, "for reward:", reward)
 
-				err = sendProof(client, spFs, storedFileBytes, nodeAddr, common.HexToAddress(spAddress), blockNum-10, posInstance) // sending blocknum that we used for verifying proof
+				metrics.ProofAttempts.Inc(networks.Current())
+
+				err = sendProof(tracker, client, storedFileBytes, nodeAddr, common.HexToAddress(spAddress), spFs, blockNum-10, posInstance) // sending blocknum that we used for verifying proof
 				if err != nil {
-					logger.Log(logger.MarkLocation(location, err))
+					logger.Error(logger.MarkLocation(location, err), logger.Fields{"network": networks.Current(), "sp": spAddress, "file": fileName})
 					continue
 				} else {
 
@@ -442,19 +542,45 @@ func StartMakingProofs(nodeAddr common.Address, password string, nodeConfig node
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-// SendProof checks Storage Providers's file system root hash and nounce info and sends proof to smart contract.
-func sendProof(client *ethclient.Client, spFs nodeTypes.StorageProviderData, fileBytes []byte, nodeAddr common.Address, spAddress common.Address,
-	blockNum uint64, posInstance *PoS.Pos) error {
+// PauseProofs stops sending proofs until ResumeProofs is called, proofs worker keeps running.
+func PauseProofs() {
+	atomic.StoreInt32(&proofsPaused, 1)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func ResumeProofs() {
+	atomic.StoreInt32(&proofsPaused, 0)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func ProofsPaused() bool {
+	return atomic.LoadInt32(&proofsPaused) == 1
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// SendProof checks Storage Providers's file system info and sends proof to smart contract.
+// Proof is built on the passed fs snapshot selected by selectFsSnapshot, so fs updates
+// that are not confirmed yet don't block proofs.
+func sendProof(tracker *TxTracker, client *ethclient.Client, fileBytes []byte, nodeAddr common.Address, spAddress common.Address,
+	fsHeader nodeTypes.StorageProviderData, blockNum uint64, posInstance *PoS.Pos) error {
 
 	const location = "blckChain.sendProof->"
 
-	balanceIsLow, err := checkBalance(client, nodeAddr, blockNum, false)
+	balanceIsLow, err := checkBalance(client, nodeAddr, blockNum)
 	if err != nil {
-		return logger.MarkLocation(location, err)
+		return proofFailed("balance", logger.MarkLocation(location, err))
 	}
 
 	if balanceIsLow {
-		return logger.MarkLocation(location, errors.New("not sufficient funds for transactions"))
+		return proofFailed("balance", logger.MarkLocation(location, ErrLowBalance))
+	}
+
+	spFs, err := fsysInfo.Snapshot(networks.Current(), spAddress.String(), fsHeader.Root)
+	if err != nil {
+		return proofFailed("fs_snapshot", logger.MarkLocation(location, err))
 	}
 
 	eightKBHashes := []string{}
@@ -466,7 +592,7 @@ func sendProof(client *ethclient.Client, spFs nodeTypes.StorageProviderData, fil
 
 	_, fileTree, err := hash.CalcRoot(eightKBHashes)
 	if err != nil {
-		return logger.MarkLocation(location, err)
+		return proofFailed("file_tree", logger.MarkLocation(location, err))
 	}
 
 	eightKBHashes = nil
@@ -486,43 +612,11 @@ func sendProof(client *ethclient.Client, spFs nodeTypes.StorageProviderData, fil
 	path := makePath(fileTree[0][0], treeToFsRoot)
 
 	if len(path) == 0 {
-		return logger.MarkLocation(location, errors.New("proof is empty"))
+		return proofFailed("empty_proof", logger.MarkLocation(location, errors.New("proof is empty")))
 	}
 
 	fsRootHashBytes := path[len(path)-1]
 
-	contractRootHash, contractNonce, err := posInstance.GetUserRootHash(&bind.CallOpts{}, spAddress)
-	if err != nil {
-		return logger.MarkLocation(location, err)
-	}
-
-	var zeroHash [32]byte
-
-	contractFsHashIsZero := bytes.Equal(zeroHash[:], contractRootHash[:])
-
-	curentNonceIsZero := contractNonce.Cmp(big.NewInt(int64(0))) == 0
-
-	firstProof := curentNonceIsZero && contractFsHashIsZero
-
-	if !firstProof {
-		contractNonceIsBigger := contractNonce.Cmp(big.NewInt(int64(spFs.Nonce))) == 1
-
-		rootHashesEqual := bytes.Equal(fsRootHashBytes[:], contractRootHash[:])
-
-		if contractNonceIsBigger && !rootHashesEqual {
-			if contractNonceIsBigger {
-				fmt.Println("Contract nonce is bigger, contract nonce:", contractNonce, "provider nonce:", spFs.Nonce)
-			}
-
-			if !rootHashesEqual {
-				fmt.Println("contract root hash is not equal to provider root hash")
-			}
-
-			return logger.MarkLocation(location, errors.New("fs root hash info is not valid"))
-
-		}
-	}
-
 	nonceBytes := make([]byte, 4)
 
 	binary.BigEndian.PutUint32(nonceBytes, spFs.Nonce)
@@ -543,12 +637,12 @@ func sendProof(client *ethclient.Client, spFs nodeTypes.StorageProviderData, fil
 
 	err = sign.Check(spAddress.String(), spFs.SignedFsInfo, sha256.Sum256(fsRootStorageNonceBytes))
 	if err != nil {
-		return logger.MarkLocation(location, err)
+		return proofFailed("fs_signature", logger.MarkLocation(location, err))
 	}
 
 	signedFSRootNonceStorage, err := hex.DecodeString(spFs.SignedFsInfo)
 	if err != nil {
-		return logger.MarkLocation(location, err)
+		return proofFailed("fs_signature", logger.MarkLocation(location, err))
 	}
 
 	if signedFSRootNonceStorage[len(signedFSRootNonceStorage)-1] == 1 { //ecdsa version fix
@@ -557,10 +651,10 @@ func sendProof(client *ethclient.Client, spFs nodeTypes.StorageProviderData, fil
 		signedFSRootNonceStorage = signedFSRootNonceStorage[:64]
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	sendCtx, cancel := context.WithTimeout(tracker.Context(), time.Second*30)
 	defer cancel()
 
-	proofOpts.Context = ctx
+	proofOpts.Context = sendCtx
 
 	trx, err := posInstance.SendProof(proofOpts, common.HexToAddress(spAddress.String()), uint32(blockNum), fsRootHashBytes, uint64(spFs.Storage), uint64(spFs.Nonce), signedFSRootNonceStorage, fileBytes[:eightKB], path)
 	if err != nil {
@@ -575,18 +669,22 @@ func sendProof(client *ethclient.Client, spFs nodeTypes.StorageProviderData, fil
 			trx, err = posInstance.SendProof(proofOpts, common.HexToAddress(spAddress.String()), uint32(blockNum), fsRootHashBytes, uint64(spFs.Storage), uint64(spFs.Nonce), signedFSRootNonceStorage, fileBytes[:eightKB], path)
 			if err != nil {
 				debug.FreeOSMemory()
-				return logger.MarkLocation(location, err)
+				return proofFailed("transaction", logger.MarkLocation(location, err))
 			}
 
 		} else {
 			debug.FreeOSMemory()
 			proofOpts.Nonce = proofOpts.Nonce.Add(proofOpts.Nonce, big.NewInt(int64(1)))
-			return logger.MarkLocation(location, err)
+			return proofFailed("transaction", logger.MarkLocation(location, err))
 		}
 	}
 
 	fmt.Printf("transaction hash: %v\n", fmt.Sprint(networks.Fields().TRX, trx.Hash()))
 
+	health.SetLastProof(trx.Hash().Hex(), "sent")
+
+	tracker.Track(client, trx)
+
 	debug.FreeOSMemory()
 	proofOpts.Nonce = proofOpts.Nonce.Add(proofOpts.Nonce, big.NewInt(int64(1)))
 
@@ -595,7 +693,115 @@ func sendProof(client *ethclient.Client, spFs nodeTypes.StorageProviderData, fil
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-func checkBalance(client *ethclient.Client, nodeAddr common.Address, blockNum uint64, exitOnLow bool) (bool, error) {
+// proofFailed counts proof that was not sent because of reason.
+func proofFailed(reason string, err error) error {
+	metrics.ProofFailures.Inc(networks.Current(), reason)
+	return err
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// trackProofTransaction waits until proof transaction is mined and counts its result and fee.
+// Transaction is not counted if ctx is done before that.
+func trackProofTransaction(ctx context.Context, backend bind.DeployBackend, trx *types.Transaction) {
+	const location = "blckChain.trackProofTransaction->"
+
+	network := networks.Current()
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute*10)
+	defer cancel()
+
+	receipt, err := bind.WaitMined(waitCtx, backend, trx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		metrics.ProofFailures.Inc(network, "not_mined")
+		logger.Error(logger.MarkLocation(location, err), logger.Fields{"network": network, "tx": trx.Hash().Hex()})
+		return
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), trx.GasPrice())
+
+	feeWei, _ := new(big.Float).SetInt(fee).Float64()
+
+	metrics.GasSpent.Add(feeWei, network)
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		metrics.ProofFailures.Inc(network, "reverted")
+		health.SetLastProof(trx.Hash().Hex(), "reverted")
+		logger.Warn("proof transaction reverted", logger.Fields{"network": network, "tx": trx.Hash().Hex()})
+		return
+	}
+
+	metrics.ProofSuccesses.Inc(network)
+	health.SetLastProof(trx.Hash().Hex(), "mined")
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// selectFsSnapshot returns header of stored fs snapshot that smart contract accepts, its tree is not loaded.
+// Snapshot with the same root hash as in smart contract is preferred, otherwise the latest snapshot is used
+// if its nonce is not lower than contract nonce. Snapshots older than the confirmed one are removed.
+func selectFsSnapshot(spAddress string, contractRootHash [32]byte, contractNonce *big.Int) (nodeTypes.StorageProviderData, error) {
+	const location = "blckChain.selectFsSnapshot->"
+
+	var spFs nodeTypes.StorageProviderData
+
+	history, err := fsysInfo.History(networks.Current(), spAddress)
+	if err != nil {
+		return spFs, logger.MarkLocation(location, err)
+	}
+
+	var zeroHash [32]byte
+
+	firstProof := contractNonce.Cmp(big.NewInt(0)) == 0 && bytes.Equal(zeroHash[:], contractRootHash[:])
+
+	if !firstProof {
+		for _, snapshot := range history {
+			if !bytes.Equal(snapshot.Root, contractRootHash[:]) {
+				continue
+			}
+
+			err = fsysInfo.Prune(networks.Current(), spAddress, snapshot.Nonce)
+			if err != nil {
+				logger.Log(logger.MarkLocation(location, err))
+			}
+
+			return snapshot, nil
+		}
+
+		if contractNonce.Cmp(big.NewInt(int64(history[0].Nonce))) == 1 {
+			fmt.Println("Contract nonce is bigger, contract nonce:", contractNonce, "provider nonce:", history[0].Nonce)
+			fmt.Println("contract root hash is not equal to provider root hash")
+
+			return spFs, logger.MarkLocation(location, errors.New("fs root hash info is not valid"))
+		}
+	}
+
+	return history[0], nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// sleep waits for d and returns false if ctx is done earlier.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// checkBalance returns true if node balance is too low to pay transaction fees.
+func checkBalance(client *ethclient.Client, nodeAddr common.Address, blockNum uint64) (bool, error) {
 
 	const location = "blckChain.checkBalance->"
 
@@ -608,19 +814,17 @@ func checkBalance(client *ethclient.Client, nodeAddr common.Address, blockNum ui
 		return false, logger.MarkLocation(location, err)
 	}
 
+	balanceWei, _ := new(big.Float).SetInt(nodeBalance).Float64()
+
+	metrics.WalletBalance.Set(balanceWei, networks.Current())
+
 	nodeBalanceIsLow := nodeBalance.Cmp(big.NewInt(1500000000000000)) == -1
 
 	if nodeBalanceIsLow {
 		fmt.Println("Insufficient funds for paying", networks.Current(), "transaction fees. Balance:", nodeBalance)
 		fmt.Println("Please top up your balance")
 
-		if exitOnLow {
-			fmt.Println("Exited")
-			os.Exit(0)
-		} else {
-			return true, nil
-		}
-
+		return true, nil
 	}
 
 	return false, nil
@@ -720,7 +924,9 @@ func makePath(start []byte, tree [][][]byte) [][32]byte {
 
 		path = append(path, secondNode)
 
-		concatBytes := append(tree[stage][firstNodePosition], tree[stage][secondNodePosition]...)
+		concatBytes := make([]byte, 0, len(tree[stage][firstNodePosition])+len(tree[stage][secondNodePosition]))
+		concatBytes = append(concatBytes, tree[stage][firstNodePosition]...)
+		concatBytes = append(concatBytes, tree[stage][secondNodePosition]...)
 		hSum := sha256.Sum256(concatBytes)
 
 		start = hSum[:]
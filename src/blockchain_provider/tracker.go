@@ -0,0 +1,75 @@
+package blckChain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxTracker waits for sent proof transactions to be mined. Its context doesn't depend on the proofs worker,
+// so transactions that are pending when worker stops are still counted until Drain gives up on them.
+type TxTracker struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func NewTxTracker() *TxTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &TxTracker{ctx: ctx, cancel: cancel}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Context is done when tracker is drained, transactions are sent with it too, so sending isn't interrupted by worker stop.
+func (t *TxTracker) Context() context.Context {
+	return t.ctx
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Track waits for transaction in background and counts its result and fee.
+func (t *TxTracker) Track(backend bind.DeployBackend, trx *types.Transaction) {
+	t.wg.Add(1)
+
+	go func() {
+		defer t.wg.Done()
+		trackProofTransaction(t.ctx, backend, trx)
+	}()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Drain waits until tracked transactions are mined, transactions that are still pending after timeout are dropped.
+// Returns false if some transactions were dropped.
+func (t *TxTracker) Drain(timeout time.Duration) bool {
+	drained := make(chan struct{})
+
+	go func() {
+		t.wg.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+		t.cancel()
+		return true
+	case <-timer.C:
+	}
+
+	t.cancel()
+	<-drained
+
+	return false
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,98 @@
+package blckChain_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	blckChain "github.com/DeNetPRO/src/blockchain_provider"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend returns transaction receipt only after transaction is mined.
+type fakeBackend struct {
+	mutex    sync.Mutex
+	mined    bool
+	receipts int
+}
+
+func (b *fakeBackend) mine() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.mined = true
+}
+
+func (b *fakeBackend) returnedReceipts() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.receipts
+}
+
+func (b *fakeBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.mined {
+		return nil, ethereum.NotFound
+	}
+
+	b.receipts++
+
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: txHash, GasUsed: 21000}, nil
+}
+
+func (b *fakeBackend) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func newTransaction() *types.Transaction {
+	return types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+}
+
+func TestTrackerOutlivesWorker(t *testing.T) {
+	backend := &fakeBackend{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	drained := make(chan bool)
+
+	// worker sends transaction and stops, like proofs worker does
+	go func() {
+		tracker := blckChain.NewTxTracker()
+		tracker.Track(backend, newTransaction())
+
+		<-ctx.Done()
+
+		drained <- tracker.Drain(10 * time.Second)
+	}()
+
+	// worker is stopped while transaction is pending
+	cancel()
+
+	time.Sleep(100 * time.Millisecond)
+	backend.mine()
+
+	require.True(t, <-drained)
+	require.Equal(t, 1, backend.returnedReceipts())
+}
+
+func TestTrackerDrainTimeout(t *testing.T) {
+	backend := &fakeBackend{}
+
+	tracker := blckChain.NewTxTracker()
+	tracker.Track(backend, newTransaction())
+
+	start := time.Now()
+
+	require.False(t, tracker.Drain(100*time.Millisecond))
+	require.WithinDuration(t, start.Add(100*time.Millisecond), time.Now(), time.Second)
+	require.Error(t, tracker.Context().Err())
+	require.Zero(t, backend.returnedReceipts())
+}
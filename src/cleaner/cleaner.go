@@ -1,11 +1,9 @@
 package cleaner
 
 import (
-	"encoding/hex"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -13,244 +11,386 @@ import (
 	"time"
 
 	"github.com/DeNetPRO/src/config"
+	fsysInfo "github.com/DeNetPRO/src/fsys_info"
 	"github.com/DeNetPRO/src/networks"
 	nodeFile "github.com/DeNetPRO/src/node_file"
 	nodeTypes "github.com/DeNetPRO/src/node_types"
-	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	spFiles "github.com/DeNetPRO/src/sp_files"
 
+	"github.com/DeNetPRO/src/health"
 	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/metrics"
 	"github.com/DeNetPRO/src/paths"
 )
 
-const oneMB = 1048576
+const (
+	oneMB       = 1048576
+	runInterval = 10 * time.Minute
+)
 
 var mutex sync.Mutex
 
-var unusedFiles = map[string]bool{}
+// runMutex serializes cleaner passes and restores, while state is locked only to be read and saved,
+// so MarkOrphaned and ReportBalance don't wait for disk work of the pass.
+var runMutex sync.Mutex
+
+var (
+	confMutex     sync.Mutex
+	cleanerConfig = config.DefaultCleanerConfig
+)
 
-// Starts cleaner, that checks if stored file part is in Storage Provider's file system and deletes it if it was not found.
-func Start() {
+var regAddr = regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
+
+// Starts cleaner, that checks if stored file part is in Storage Provider's file system.
+// Parts that were not found are moved to quarantine after grace period and deleted after quarantine period.
+// Cleaner runs until ctx is done.
+func Start(ctx context.Context, conf nodeTypes.CleanerConfig) error {
 	const location = "cleaner.Start->"
 
-	var markedToDelete = map[string]int64{} // in case if file was uploaded but fs tree wasn't updated yet
+	SetConfig(conf)
+
+	health.Register(health.Cleaner, 3*runInterval)
 
-	regAddr := regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
-	regFileName := regexp.MustCompile("[0-9A-Za-z_]")
+	ticker := time.NewTicker(runInterval)
+	defer ticker.Stop()
 
 	for {
-		time.Sleep(time.Minute * 10)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
 
-		currentNets := networks.List()
+		_, err := Run(false)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+		}
 
-		for _, network := range currentNets {
-			pathToAccStorage := filepath.Join(paths.List().Storages[0], network)
+		health.Beat(health.Cleaner)
+	}
+}
 
-			stat, err := os.Stat(pathToAccStorage)
-			if err != nil && !errors.Is(err, os.ErrNotExist) {
-				logger.Log(logger.MarkLocation(location, err))
-				log.Fatal(err)
-			}
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-			if stat == nil {
-				continue
-			}
+// SetConfig sets cleaner periods, zero values are replaced with defaults.
+func SetConfig(conf nodeTypes.CleanerConfig) {
+	if conf.GracePeriod <= 0 {
+		conf.GracePeriod = config.DefaultCleanerConfig.GracePeriod
+	}
 
-			dirFiles, err := nodeFile.ReadDirFiles(pathToAccStorage)
-			if err != nil {
-				logger.Log(logger.MarkLocation(location, err))
-				continue
-			}
+	if conf.QuarantinePeriod <= 0 {
+		conf.QuarantinePeriod = config.DefaultCleanerConfig.QuarantinePeriod
+	}
 
-			storageProviderAddresses := []string{}
+	if conf.EvictionGracePeriod <= 0 {
+		conf.EvictionGracePeriod = config.DefaultCleanerConfig.EvictionGracePeriod
+	}
 
-			for _, f := range dirFiles {
-				if regAddr.MatchString(f.Name()) {
-					storageProviderAddresses = append(storageProviderAddresses, f.Name())
-				}
-			}
+	confMutex.Lock()
+	cleanerConfig = conf
+	confMutex.Unlock()
+}
 
-			if len(storageProviderAddresses) == 0 {
-				continue
-			}
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-			removedTotal := 0
+// Run makes a single cleaner pass over stored parts. In dry run mode nothing is changed, report shows what would be done.
+func Run(dryRun bool) (nodeTypes.CleanerReport, error) {
+	const location = "cleaner.Run->"
 
-			for _, spAddress := range storageProviderAddresses {
+	report := nodeTypes.CleanerReport{}
 
-				pathToStorProviderFiles := filepath.Join(pathToAccStorage, spAddress)
+	confMutex.Lock()
+	conf := cleanerConfig
+	confMutex.Unlock()
 
-				// _, unusedFiles := unusedFiles[spAddress]
+	runMutex.Lock()
+	defer runMutex.Unlock()
 
-				// if unusedFiles {
+	stateMutex.Lock()
+	snapshot, err := loadState()
+	stateMutex.Unlock()
+	if err != nil {
+		return report, logger.MarkLocation(location, err)
+	}
 
-				// 	dirFiles, err := nodeFile.ReadDirFiles(pathToStorProviderFiles)
-				// 	if err != nil {
-				// 		logger.Log(logger.MarkLocation(location, err))
-				// 		continue
-				// 	}
+	state := snapshot.copy()
 
-				// 	err = os.RemoveAll(pathToStorProviderFiles)
-				// 	if err != nil {
-				// 		continue
-				// 	}
+	now := time.Now().Unix()
 
-				// 	fmt.Println("removed", len(dirFiles), "unused files of", spAddress)
+	removedTotal := 0
 
-				// 	err = restoreSpaceInConfig(len(dirFiles))
-				// 	if err != nil {
-				// 		logger.Log(logger.MarkLocation(location, err))
-				// 		continue
-				// 	}
+	// legacy fs info is converted on read, dry run must not change anything on disk
+	leaves := fsysInfo.Leaves
+	if dryRun {
+		leaves = fsysInfo.PeekLeaves
+	}
 
-				// 	unmarkUnused(spAddress)
-				// 	continue
-				// }
+	for _, network := range networks.List() {
+		pathToAccStorage := filepath.Join(paths.List().Storages[0], network)
 
-				dirFiles, err := nodeFile.ReadDirFiles(pathToStorProviderFiles)
-				if err != nil {
-					logger.Log(logger.MarkLocation(location, err))
-					continue
-				}
+		stat, err := os.Stat(pathToAccStorage)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return report, logger.MarkLocation(location, err)
+		}
 
-				fileNames := []string{}
+		if stat == nil {
+			continue
+		}
 
-				for _, f := range dirFiles {
-					if len(f.Name()) == 64 && regFileName.MatchString(f.Name()) {
-						fileNames = append(fileNames, f.Name())
-					}
-				}
+		dirFiles, err := nodeFile.ReadDirFiles(pathToAccStorage)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			continue
+		}
+
+		for _, f := range dirFiles {
+			if !regAddr.MatchString(f.Name()) {
+				continue
+			}
+
+			spAddress := f.Name()
 
-				pathToFsTree := filepath.Join(paths.List().Storages[0], network, spAddress, paths.List().SpFsFilename)
+			payment, unpaid := state.Unpaid[spKey(network, spAddress)]
 
-				if len(fileNames) == 0 {
-					err := os.Remove(pathToFsTree)
-					if err != nil {
-						logger.Log(logger.MarkLocation(location, err))
-					}
+			if unpaid && now-payment.Since >= conf.EvictionGracePeriod && !isProtected(conf, spAddress) {
+				report.Evicted = append(report.Evicted, spKey(network, spAddress))
 
-					err = os.Remove(pathToStorProviderFiles)
-					if err != nil {
-						logger.Log(logger.MarkLocation(location, err))
-					}
+				if dryRun {
 					continue
 				}
 
-				treeBytes, err := os.ReadFile(pathToFsTree)
+				removed, err := evict(&state, network, spAddress)
 				if err != nil {
 					logger.Log(logger.MarkLocation(location, err))
+				}
+
+				removedTotal += removed
+				continue
+			}
+
+			pathToStorProviderFiles := filepath.Join(pathToAccStorage, spAddress)
+
+			fileNames, err := spFiles.PartNames(pathToStorProviderFiles)
+			if err != nil {
+				logger.Log(logger.MarkLocation(location, err))
+				continue
+			}
+
+			if len(fileNames) == 0 {
+				if dryRun || state.hasQuarantined(network, spAddress) {
 					continue
 				}
 
-				var spFs nodeTypes.StorageProviderData
+				err := fsysInfo.Remove(network, spAddress)
+				if err != nil {
+					logger.Log(logger.MarkLocation(location, err))
+				}
 
-				err = json.Unmarshal(treeBytes, &spFs)
+				err = os.Remove(pathToStorProviderFiles)
 				if err != nil {
 					logger.Log(logger.MarkLocation(location, err))
+				}
+				continue
+			}
+
+			fsInfo, err := leaves(network, spAddress)
+			if err != nil {
+				logger.Log(logger.MarkLocation(location, err))
+				continue
+			}
+
+			if len(fsInfo) == 0 {
+				continue
+			}
+
+			for _, fileName := range fileNames {
+				key := partKey(network, spAddress, fileName)
+
+				if fsInfo[fileName] {
+					delete(state.Candidates, key)
 					continue
 				}
 
-				fsInfo := map[string]bool{}
+				candidate, marked := state.Candidates[key]
 
-				for _, hashes := range spFs.Tree {
-					for _, hash := range hashes {
-						fsInfo[hex.EncodeToString(hash)] = true
-					}
+				if !marked {
+					state.Candidates[key] = partRecord{Network: network, SpAddress: spAddress, FileName: fileName, Since: now}
+					report.Marked = append(report.Marked, key)
+					continue
 				}
 
-				if len(fsInfo) == 0 {
+				if now-candidate.Since < conf.GracePeriod {
+					continue
+				}
+
+				report.Quarantined = append(report.Quarantined, key)
+
+				if dryRun {
 					continue
 				}
 
-				for _, fileName := range fileNames {
+				err := quarantine(candidate)
+				if err != nil {
+					logger.Log(logger.MarkLocation(location, err))
+					continue
+				}
 
-					_, marked := markedToDelete[fileName]
+				fmt.Println("quarantined file: " + fileName + " of " + spAddress)
 
-					if !fsInfo[fileName] {
+				delete(state.Candidates, key)
+				candidate.Since = now
+				state.Quarantined[key] = candidate
+			}
+		}
+	}
 
-						if !marked {
-							markedToDelete[fileName] = time.Now().Unix()
-							continue
-						}
+	for key, record := range state.Quarantined {
+		fsInfo, err := leaves(record.Network, record.SpAddress)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Log(logger.MarkLocation(location, err))
+			continue
+		}
 
-						if time.Now().Unix()-markedToDelete[fileName] > 60*60*2 {
-							mutex.Lock()
-							fmt.Println("removing file: " + fileName + " of " + spAddress)
-							stat, err := os.Stat(filepath.Join(pathToStorProviderFiles, fileName))
-							if err != nil {
-								mutex.Unlock()
-								logger.Log(logger.MarkLocation(location, err))
-								continue
-							}
+		if fsInfo[record.FileName] {
+			report.Restored = append(report.Restored, key)
 
-							err = os.Remove(filepath.Join(pathToStorProviderFiles, fileName))
-							if err != nil {
-								mutex.Unlock()
-								logger.Log(logger.MarkLocation(location, err))
-								continue
-							}
+			if dryRun {
+				continue
+			}
 
-							if !tstpkg.Data().TestMode {
-								logger.SendStatistic(spAddress, network, "", logger.Delete, stat.Size())
-							}
+			replaced, err := restore(record)
+			if err != nil {
+				logger.Log(logger.MarkLocation(location, err))
+				continue
+			}
 
-							removedTotal++
+			if replaced {
+				removedTotal++
+			}
 
-							delete(markedToDelete, fileName)
+			fmt.Println("restored file: " + record.FileName + " of " + record.SpAddress)
 
-							mutex.Unlock()
-						}
+			delete(state.Quarantined, key)
+			continue
+		}
 
-					} else {
-						if marked {
-							delete(markedToDelete, fileName)
-						}
-					}
+		if now-record.Since < conf.QuarantinePeriod {
+			continue
+		}
 
-				}
+		report.Deleted = append(report.Deleted, key)
 
-			}
+		if dryRun {
+			continue
+		}
 
-			if removedTotal > 0 {
-				err := restoreSpaceInConfig(removedTotal)
-				if err != nil {
-					logger.Log(logger.MarkLocation(location, err))
-					continue
-				}
-			}
+		err = remove(record)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			continue
 		}
 
+		removedTotal++
+
+		delete(state.Quarantined, key)
+	}
+
+	if dryRun {
+		return report, nil
 	}
+
+	stateMutex.Lock()
+	err = commitState(snapshot, state)
+	stateMutex.Unlock()
+	if err != nil {
+		return report, logger.MarkLocation(location, err)
+	}
+
+	if removedTotal > 0 {
+		err := restoreSpaceInConfig(removedTotal)
+		if err != nil {
+			return report, logger.MarkLocation(location, err)
+		}
+	}
+
+	return report, nil
 }
 
-func restoreSpaceInConfig(space int) error {
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-	const location = "cleaner.restoreSpaceInConfig ->"
+// Restore moves quarantined parts back to storage. If no part names are passed, all quarantined parts are restored.
+func Restore(fileNames []string) ([]string, error) {
+	const location = "cleaner.Restore->"
+
+	runMutex.Lock()
+	defer runMutex.Unlock()
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
 
-	mutex.Lock()
-	confFile, fileBytes, err := nodeFile.Read(paths.List().ConfigFile)
+	state, err := loadState()
 	if err != nil {
-		mutex.Unlock()
-		return logger.MarkLocation(location, err)
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	selected := make(map[string]bool, len(fileNames))
+
+	for _, fileName := range fileNames {
+		selected[fileName] = true
 	}
 
-	var nodeConfig nodeTypes.Config
+	restored := []string{}
+	removedTotal := 0
 
-	err = json.Unmarshal(fileBytes, &nodeConfig)
+	for key, record := range state.Quarantined {
+		if len(selected) != 0 && !selected[record.FileName] {
+			continue
+		}
+
+		replaced, err := restore(record)
+		if err != nil {
+			return restored, logger.MarkLocation(location, err)
+		}
+
+		if replaced {
+			removedTotal++
+		}
+
+		delete(state.Quarantined, key)
+		restored = append(restored, key)
+	}
+
+	err = saveState(state)
 	if err != nil {
-		mutex.Unlock()
-		confFile.Close()
-		return logger.MarkLocation(location, err)
+		return restored, logger.MarkLocation(location, err)
 	}
 
-	nodeConfig.UsedStorageSpace -= int64(space * oneMB)
+	if removedTotal > 0 {
+		err := restoreSpaceInConfig(removedTotal)
+		if err != nil {
+			return restored, logger.MarkLocation(location, err)
+		}
+	}
 
-	err = config.Save(confFile, nodeConfig)
+	return restored, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func restoreSpaceInConfig(space int) error {
+
+	const location = "cleaner.restoreSpaceInConfig ->"
+
+	err := config.Update(func(nodeConfig *nodeTypes.Config) error {
+		nodeConfig.UsedStorageSpace -= int64(space * oneMB)
+		return nil
+	})
 	if err != nil {
-		mutex.Unlock()
-		confFile.Close()
 		return logger.MarkLocation(location, err)
 	}
-	mutex.Unlock()
+
+	metrics.CleanerDeletedParts.Add(float64(space))
+	metrics.CleanerDeletedBytes.Add(float64(space * oneMB))
 
 	fmt.Println("cleaned", space, "Mbytes")
 
@@ -259,36 +399,40 @@ func restoreSpaceInConfig(space int) error {
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-func MarkUnused(spAddr string) {
-	mutex.Lock()
-
-	_, alreadyMarked := unusedFiles[spAddr]
+// MarkOrphaned starts grace period for parts that are not referenced by storage provider's fs anymore,
+// so they are quarantined without waiting for the next cleaner check.
+func MarkOrphaned(network, spAddress string, fileNames []string) {
+	const location = "cleaner.MarkOrphaned->"
 
-	if alreadyMarked {
-		mutex.Unlock()
+	if len(fileNames) == 0 {
 		return
 	}
 
-	unusedFiles[spAddr] = true
-	fmt.Println("marked", spAddr, "files as unused")
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
 
-	mutex.Unlock()
-}
+	state, err := loadState()
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+		return
+	}
 
-// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+	now := time.Now().Unix()
 
-func unmarkUnused(spAddr string) {
-	mutex.Lock()
+	for _, fileName := range fileNames {
+		key := partKey(network, spAddress, fileName)
 
-	_, alreadyUnarked := unusedFiles[spAddr]
+		_, alreadyMarked := state.Candidates[key]
 
-	if alreadyUnarked {
-		mutex.Unlock()
-		return
+		if !alreadyMarked {
+			state.Candidates[key] = partRecord{Network: network, SpAddress: spAddress, FileName: fileName, Since: now}
+		}
 	}
 
-	delete(unusedFiles, spAddr)
-	mutex.Unlock()
+	err = saveState(state)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
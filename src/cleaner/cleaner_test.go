@@ -0,0 +1,246 @@
+package cleaner_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/cleaner"
+	"github.com/DeNetPRO/src/config"
+	fsysInfo "github.com/DeNetPRO/src/fsys_info"
+	"github.com/DeNetPRO/src/hash"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/pb"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	network   = "kovan"
+	spAddress = "0x0000000000000000000000000000000000000001"
+)
+
+func TestMain(m *testing.M) {
+	tstpkg.TestModeOn()
+	defer tstpkg.TestModeOff()
+
+	err := paths.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = config.Create(tstpkg.Data().AccAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	exitVal := m.Run()
+
+	err = os.RemoveAll(paths.List().WorkDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitVal)
+}
+
+func TestQuarantine(t *testing.T) {
+	pathToSpFiles := filepath.Join(paths.List().Storages[0], network, spAddress)
+
+	err := os.MkdirAll(pathToSpFiles, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := make([]string, 0, 4)
+
+	for i := 0; i < 4; i++ {
+		hSum := sha256.Sum256([]byte(fmt.Sprint(spAddress, i)))
+		parts = append(parts, hex.EncodeToString(hSum[:]))
+
+		err = os.WriteFile(filepath.Join(pathToSpFiles, parts[i]), []byte{byte(i)}, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, tree, err := hash.CalcRoot(parts[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fsysInfo.Save(&pb.FsInfo{Network: network, SpAddress: spAddress, Nonce: 1, Storage: 2}, tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleaner.SetConfig(nodeTypes.CleanerConfig{GracePeriod: 1, QuarantinePeriod: 1})
+
+	report, err := cleaner.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(t, report.Marked, 2)
+	require.Empty(t, report.Quarantined)
+
+	time.Sleep(time.Second)
+
+	report, err = cleaner.Run(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(t, report.Quarantined, 2)
+	require.FileExists(t, filepath.Join(pathToSpFiles, parts[2]))
+
+	report, err = cleaner.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(t, report.Quarantined, 2)
+	require.NoFileExists(t, filepath.Join(pathToSpFiles, parts[2]))
+	require.NoFileExists(t, filepath.Join(pathToSpFiles, parts[3]))
+	require.FileExists(t, filepath.Join(pathToSpFiles, parts[0]))
+
+	restored, err := cleaner.Restore([]string{parts[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(t, restored, 1)
+	require.FileExists(t, filepath.Join(pathToSpFiles, parts[2]))
+
+	time.Sleep(time.Second)
+
+	report, err = cleaner.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(t, report.Deleted, 1)
+	require.Len(t, report.Marked, 1)
+	require.FileExists(t, filepath.Join(pathToSpFiles, parts[2]))
+}
+
+func TestEviction(t *testing.T) {
+	const (
+		unpaidSp    = "0x0000000000000000000000000000000000000002"
+		protectedSp = "0x0000000000000000000000000000000000000003"
+	)
+
+	for _, sp := range []string{unpaidSp, protectedSp} {
+		pathToSpFiles := filepath.Join(paths.List().Storages[0], network, sp)
+
+		err := os.MkdirAll(pathToSpFiles, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hSum := sha256.Sum256([]byte(sp))
+		part := hex.EncodeToString(hSum[:])
+
+		err = os.WriteFile(filepath.Join(pathToSpFiles, part), []byte{1}, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, tree, err := hash.CalcRoot([]string{part})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = fsysInfo.Save(&pb.FsInfo{Network: network, SpAddress: sp, Nonce: 1, Storage: 1}, tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cleaner.SetConfig(nodeTypes.CleanerConfig{EvictionGracePeriod: 1, ProtectedSPs: []string{protectedSp}})
+
+	cleaner.ReportBalance(network, unpaidSp, false)
+	cleaner.ReportBalance(network, protectedSp, false)
+
+	report, err := cleaner.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Empty(t, report.Evicted)
+
+	time.Sleep(time.Second)
+
+	report, err = cleaner.Run(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, []string{network + "/" + unpaidSp}, report.Evicted)
+	require.DirExists(t, filepath.Join(paths.List().Storages[0], network, unpaidSp))
+
+	report, err = cleaner.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, []string{network + "/" + unpaidSp}, report.Evicted)
+	require.NoDirExists(t, filepath.Join(paths.List().Storages[0], network, unpaidSp))
+	require.DirExists(t, filepath.Join(paths.List().Storages[0], network, protectedSp))
+
+	cleaner.ReportBalance(network, protectedSp, true)
+}
+
+func TestDryRunKeepsLegacyFs(t *testing.T) {
+	const legacySp = "0x0000000000000000000000000000000000000004"
+
+	pathToSpFiles := filepath.Join(paths.List().Storages[0], network, legacySp)
+
+	err := os.MkdirAll(pathToSpFiles, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := make([]string, 0, 3)
+
+	for i := 0; i < 3; i++ {
+		hSum := sha256.Sum256([]byte(fmt.Sprint(legacySp, i)))
+		parts = append(parts, hex.EncodeToString(hSum[:]))
+
+		err = os.WriteFile(filepath.Join(pathToSpFiles, parts[i]), []byte{byte(i)}, 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, tree, err := hash.CalcRoot(parts[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacyFs, err := json.Marshal(nodeTypes.StorageProviderData{Nonce: 1, Storage: 2, Tree: tree})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(pathToSpFiles, "sp_fs.json"), legacyFs, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := cleaner.Run(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Contains(t, report.Marked, network+"/"+legacySp+"/"+parts[2])
+	require.FileExists(t, filepath.Join(pathToSpFiles, "sp_fs.json"))
+	require.NoFileExists(t, filepath.Join(pathToSpFiles, paths.List().SpFsFilename))
+}
@@ -0,0 +1,158 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fsysInfo "github.com/DeNetPRO/src/fsys_info"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/metrics"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/quota"
+	spFiles "github.com/DeNetPRO/src/sp_files"
+	"github.com/DeNetPRO/src/telemetry"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+)
+
+// ReportBalance keeps storage provider's payment status reported by the proof loop.
+// Files of storage provider that stays unpaid longer than eviction grace period are removed by cleaner.
+func ReportBalance(network, spAddress string, paid bool) {
+	const location = "cleaner.ReportBalance->"
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	state, err := loadState()
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+		return
+	}
+
+	key := spKey(network, spAddress)
+
+	_, unpaid := state.Unpaid[key]
+
+	if paid == !unpaid {
+		return
+	}
+
+	if paid {
+		delete(state.Unpaid, key)
+		logger.Info("storage provider's balance is restored, files won't be removed", logger.Fields{"network": network, "sp": spAddress})
+	} else {
+		state.Unpaid[key] = paymentRecord{Since: time.Now().Unix()}
+
+		confMutex.Lock()
+		conf := cleanerConfig
+		confMutex.Unlock()
+
+		if isProtected(conf, spAddress) {
+			logger.Warn("storage provider's balance is zero, files are protected from removal", logger.Fields{"network": network, "sp": spAddress})
+		} else {
+			logger.Warn("storage provider's balance is zero, files will be removed after grace period", logger.Fields{
+				"network":     network,
+				"sp":          spAddress,
+				"gracePeriod": (time.Duration(conf.EvictionGracePeriod) * time.Second).String(),
+			})
+		}
+	}
+
+	err = saveState(state)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func isProtected(conf nodeTypes.CleanerConfig, spAddress string) bool {
+	for _, protected := range conf.ProtectedSPs {
+		if strings.EqualFold(protected, spAddress) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// evict removes all stored and quarantined parts of storage provider and returns count of removed parts.
+// Passed state is changed accordingly, it's saved by caller.
+func evict(state *cleanerState, network, spAddress string) (int, error) {
+	const location = "cleaner.evict->"
+
+	pathToSpFiles := filepath.Join(paths.List().Storages[0], network, spAddress)
+
+	fileNames, err := spFiles.PartNames(pathToSpFiles)
+	if err != nil {
+		return 0, logger.MarkLocation(location, err)
+	}
+
+	removed := 0
+	var removedSize int64
+
+	mutex.Lock()
+
+	for _, fileName := range fileNames {
+		stat, err := os.Stat(filepath.Join(pathToSpFiles, fileName))
+		if err != nil {
+			continue
+		}
+
+		removedSize += stat.Size()
+		removed++
+	}
+
+	err = os.RemoveAll(pathToSpFiles)
+	mutex.Unlock()
+	if err != nil {
+		return 0, logger.MarkLocation(location, err)
+	}
+
+	quota.Remove(network, spAddress, removedSize)
+
+	err = fsysInfo.Remove(network, spAddress)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+
+	for key, record := range state.Quarantined {
+		if record.Network != network || record.SpAddress != spAddress {
+			continue
+		}
+
+		err := remove(record)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			continue
+		}
+
+		delete(state.Quarantined, key)
+		removed++
+	}
+
+	for key, record := range state.Candidates {
+		if record.Network == network && record.SpAddress == spAddress {
+			delete(state.Candidates, key)
+		}
+	}
+
+	delete(state.Unpaid, spKey(network, spAddress))
+
+	if !tstpkg.Data().TestMode && removedSize > 0 {
+		telemetry.Stat(telemetry.Delete, network, spAddress, "", removedSize)
+	}
+
+	metrics.CleanerEvictions.Inc(network)
+	metrics.CleanerEvictedParts.Add(float64(removed), network)
+
+	logger.Warn("storage provider's files are removed because of zero balance", logger.Fields{"network": network, "sp": spAddress, "parts": removed})
+
+	return removed, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
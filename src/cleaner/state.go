@@ -0,0 +1,328 @@
+package cleaner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/quota"
+	"github.com/DeNetPRO/src/telemetry"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+)
+
+const (
+	stateFileName     = "cleaner.json"
+	quarantineDirName = "quarantine"
+)
+
+var stateMutex sync.Mutex
+
+type partRecord struct {
+	Network   string `json:"network"`
+	SpAddress string `json:"spAddress"`
+	FileName  string `json:"fileName"`
+	Since     int64  `json:"since"`
+}
+
+type paymentRecord struct {
+	Since int64 `json:"since"`
+}
+
+// cleanerState is kept on disk, so grace and quarantine periods survive node restarts.
+type cleanerState struct {
+	Candidates  map[string]partRecord    `json:"candidates"`
+	Quarantined map[string]partRecord    `json:"quarantined"`
+	Unpaid      map[string]paymentRecord `json:"unpaid"`
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func partKey(network, spAddress, fileName string) string {
+	return spKey(network, spAddress) + "/" + fileName
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func spKey(network, spAddress string) string {
+	return network + "/" + spAddress
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (s cleanerState) hasQuarantined(network, spAddress string) bool {
+	for _, record := range s.Quarantined {
+		if record.Network == network && record.SpAddress == spAddress {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (s cleanerState) copy() cleanerState {
+	copied := cleanerState{
+		Candidates:  make(map[string]partRecord, len(s.Candidates)),
+		Quarantined: make(map[string]partRecord, len(s.Quarantined)),
+		Unpaid:      make(map[string]paymentRecord, len(s.Unpaid)),
+	}
+
+	for key, record := range s.Candidates {
+		copied.Candidates[key] = record
+	}
+
+	for key, record := range s.Quarantined {
+		copied.Quarantined[key] = record
+	}
+
+	for key, record := range s.Unpaid {
+		copied.Unpaid[key] = record
+	}
+
+	return copied
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// commitState saves changes that cleaner pass made to snapshot of the state. Changes are applied to the current state,
+// so records saved by others during the pass are kept. Must be called with stateMutex locked.
+func commitState(snapshot, changed cleanerState) error {
+	const location = "cleaner.commitState->"
+
+	state, err := loadState()
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	mergeRecords(state.Candidates, snapshot.Candidates, changed.Candidates)
+	mergeRecords(state.Quarantined, snapshot.Quarantined, changed.Quarantined)
+
+	for key := range snapshot.Unpaid {
+		_, kept := changed.Unpaid[key]
+		if !kept {
+			delete(state.Unpaid, key)
+		}
+	}
+
+	err = saveState(state)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// mergeRecords applies records that were removed, added or changed in snapshot to current records.
+func mergeRecords(current, snapshot, changed map[string]partRecord) {
+	for key := range snapshot {
+		_, kept := changed[key]
+		if !kept {
+			delete(current, key)
+		}
+	}
+
+	for key, record := range changed {
+		old, found := snapshot[key]
+		if !found || old != record {
+			current[key] = record
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// loadState must be called with stateMutex locked.
+func loadState() (cleanerState, error) {
+	const location = "cleaner.loadState->"
+
+	state := cleanerState{
+		Candidates:  map[string]partRecord{},
+		Quarantined: map[string]partRecord{},
+		Unpaid:      map[string]paymentRecord{},
+	}
+
+	stateBytes, err := os.ReadFile(filepath.Join(paths.List().Storages[0], stateFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+
+		return state, logger.MarkLocation(location, err)
+	}
+
+	err = json.Unmarshal(stateBytes, &state)
+	if err != nil {
+		return state, logger.MarkLocation(location, err)
+	}
+
+	if state.Candidates == nil {
+		state.Candidates = map[string]partRecord{}
+	}
+
+	if state.Quarantined == nil {
+		state.Quarantined = map[string]partRecord{}
+	}
+
+	if state.Unpaid == nil {
+		state.Unpaid = map[string]paymentRecord{}
+	}
+
+	return state, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// saveState must be called with stateMutex locked.
+func saveState(state cleanerState) error {
+	const location = "cleaner.saveState->"
+
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	statePath := filepath.Join(paths.List().Storages[0], stateFileName)
+
+	tmpPath := statePath + ".tmp"
+
+	err = os.WriteFile(tmpPath, stateBytes, 0600)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = os.Rename(tmpPath, statePath)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func storedPartPath(record partRecord) string {
+	return filepath.Join(paths.List().Storages[0], record.Network, record.SpAddress, record.FileName)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func quarantinedPartPath(record partRecord) string {
+	return filepath.Join(paths.List().Storages[0], quarantineDirName, record.Network, record.SpAddress, record.FileName)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// quarantine moves part out of storage provider's directory, so it can't be served but still can be restored.
+func quarantine(record partRecord) error {
+	const location = "cleaner.quarantine->"
+
+	quarantinedPath := quarantinedPartPath(record)
+
+	err := os.MkdirAll(filepath.Dir(quarantinedPath), 0700)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	stat, err := os.Stat(storedPartPath(record))
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = os.Rename(storedPartPath(record), quarantinedPath)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	quota.Remove(record.Network, record.SpAddress, stat.Size())
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// restore moves quarantined part back to storage provider's directory.
+// If the part was uploaded again meanwhile, quarantined copy is removed and replaced is true.
+func restore(record partRecord) (bool, error) {
+	const location = "cleaner.restore->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	storedPath := storedPartPath(record)
+
+	_, err := os.Stat(storedPath)
+	if err == nil {
+		err = os.Remove(quarantinedPartPath(record))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, logger.MarkLocation(location, err)
+		}
+
+		return true, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return false, logger.MarkLocation(location, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(storedPath), 0700)
+	if err != nil {
+		return false, logger.MarkLocation(location, err)
+	}
+
+	stat, err := os.Stat(quarantinedPartPath(record))
+	if err != nil {
+		return false, logger.MarkLocation(location, err)
+	}
+
+	err = os.Rename(quarantinedPartPath(record), storedPath)
+	if err != nil {
+		return false, logger.MarkLocation(location, err)
+	}
+
+	quota.Add(record.Network, record.SpAddress, stat.Size())
+
+	return false, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// remove deletes quarantined part.
+func remove(record partRecord) error {
+	const location = "cleaner.remove->"
+
+	quarantinedPath := quarantinedPartPath(record)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	fmt.Println("removing file: " + record.FileName + " of " + record.SpAddress)
+
+	stat, err := os.Stat(quarantinedPath)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = os.Remove(quarantinedPath)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if !tstpkg.Data().TestMode {
+		telemetry.Stat(telemetry.Delete, record.Network, record.SpAddress, "", stat.Size())
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -6,10 +6,9 @@ import (
 	"strings"
 
 	"github.com/DeNetPRO/src/account"
-	"github.com/DeNetPRO/src/cleaner"
 	"github.com/DeNetPRO/src/hash"
 	"github.com/DeNetPRO/src/logger"
-	"github.com/DeNetPRO/src/rpcserver"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/howeyc/gopass"
 	"github.com/spf13/cobra"
 )
@@ -60,18 +59,13 @@ var accountCreateCmd = &cobra.Command{
 		password := hash.Password(password1)
 		password1 = ""
 
-		_, nodeConfig, err := account.Create(password)
+		addr, nodeConfig, err := account.Create(password)
 		if err != nil {
 			logger.Log(logger.MarkLocation(location, err))
 			log.Fatal(accCreateFatalMessage)
 		}
 
-		go cleaner.Start()
-
-		err = rpcserver.Start(nodeConfig.HTTPPort)
-		if err != nil {
-			log.Fatal(err)
-		}
+		runNode(common.HexToAddress(addr), "", nodeConfig)
 	},
 }
 
@@ -6,7 +6,7 @@ import (
 
 	"github.com/DeNetPRO/src/account"
 	"github.com/DeNetPRO/src/logger"
-	"github.com/DeNetPRO/src/rpcserver"
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/spf13/cobra"
 )
@@ -18,18 +18,14 @@ var accountImportCmd = &cobra.Command{
 	Long:  "imports your wallet by private key",
 	Run: func(cmd *cobra.Command, args []string) {
 		const location = "accountImportCmd->"
-		_, nodeConfig, err := account.Import()
+		addr, password, nodeConfig, err := account.Import()
 		if err != nil {
 			fmt.Println(err)
 			logger.Log(logger.MarkLocation(location, err))
 			log.Fatal("Fatal error, couldn't import an account")
 		}
 
-		err = rpcserver.Start(nodeConfig.HTTPPort)
-		if err != nil {
-			log.Fatal(err)
-		}
-
+		runNode(common.HexToAddress(addr), password, nodeConfig)
 	},
 }
 
@@ -8,21 +8,20 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/DeNetPRO/src/account"
 	blckChain "github.com/DeNetPRO/src/blockchain_provider"
-	"github.com/DeNetPRO/src/cleaner"
 	"github.com/DeNetPRO/src/config"
 	"github.com/DeNetPRO/src/errs"
 	"github.com/DeNetPRO/src/logger"
 	"github.com/DeNetPRO/src/networks"
 	nodeFile "github.com/DeNetPRO/src/node_file"
 	nodeTypes "github.com/DeNetPRO/src/node_types"
-	"github.com/DeNetPRO/src/rpcserver"
+	"github.com/DeNetPRO/src/telemetry"
 
 	"github.com/DeNetPRO/src/paths"
-	"github.com/DeNetPRO/src/upnp"
 	"github.com/spf13/cobra"
 )
 
@@ -144,32 +143,6 @@ var accountLoginCmd = &cobra.Command{
 
 			}
 
-			if upnp.InternetDevice != nil {
-				ip, err := upnp.InternetDevice.PublicIP()
-				if err != nil {
-					logger.Log(logger.MarkLocation(location, err))
-				}
-
-				if registeredInNetwork && nodeConfig.IpAddress != ip {
-
-					fmt.Println("Updating public ip info...")
-
-					ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-
-					defer cancel()
-
-					err = blckChain.UpdateNodeInfo(ctx, nodeAccount.Address, password, nodeConfig.IpAddress, nodeConfig.HTTPPort)
-					if err != nil {
-						logger.Log(logger.MarkLocation(location, err))
-						log.Fatal(ipUpdateFatalError)
-					}
-
-					nodeConfig.IpAddress = ip
-					configWasUpdated = true
-
-				}
-			}
-
 			if configWasUpdated {
 				err = config.Save(confFile, nodeConfig)
 				if err != nil {
@@ -187,18 +160,16 @@ var accountLoginCmd = &cobra.Command{
 		}
 
 		paths.SetStoragePaths(nodeConfig.StoragePaths)
-		logger.SendReports = nodeConfig.SendBugReports
-
-		fmt.Println("Logged in")
-
-		go blckChain.StartMakingProofs(nodeAccount.Address, password, nodeConfig)
-
-		go cleaner.Start()
+		telemetry.SetErrorReports(nodeConfig.SendBugReports)
 
-		err = rpcserver.Start(nodeConfig.HTTPPort)
+		err = logger.Init(nodeConfig.Logging, filepath.Join(paths.List().WorkDir, "logs"))
 		if err != nil {
-			log.Fatal(err)
+			logger.Log(logger.MarkLocation(location, err))
 		}
+
+		fmt.Println("Logged in")
+
+		runNode(nodeAccount.Address, password, nodeConfig)
 	},
 }
 
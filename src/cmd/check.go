@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/DeNetPRO/src/admin"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/probe"
+	"github.com/spf13/cobra"
+)
+
+// CheckCmd is executed when "check" flag is passed and makes the running node check that it's reachable
+// by the address written in smart contract.
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "checks that the running node is reachable by its address in smart contract",
+	Long:  "reads node address from smart contract, connects to it and sends signed request, then explains what prevents storage providers from reaching the node",
+	Run: func(cmd *cobra.Command, args []string) {
+		const location = "checkCmd->"
+
+		ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+		defer cancel()
+
+		report, err := admin.NewClient(adminAddress).Check(ctx)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+
+			if errors.Is(err, admin.ErrNotRunning) {
+				log.Fatal("node must be running to be checked, log in to start it: ", err)
+			}
+
+			log.Fatal(err)
+		}
+
+		probe.Print(report)
+
+		if !report.Reachable {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/DeNetPRO/src/account"
+	"github.com/DeNetPRO/src/cleaner"
+	"github.com/DeNetPRO/src/logger"
+	nodeFile "github.com/DeNetPRO/src/node_file"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/spf13/cobra"
+)
+
+const cleanerFatalMessage = "Fatal error while cleaner check"
+
+var cleanerDryRun bool
+
+// CleanerCmd is executed when "cleaner" flag is passed. With "--dry-run" flag it reports
+// which stored parts would be marked, quarantined, restored, deleted or evicted by the next cleaner check.
+var cleanerCmd = &cobra.Command{
+	Use:   "cleaner",
+	Short: "cleaner is a command for managing unused file parts",
+	Long:  "cleaner is a command for managing unused file parts",
+	Run: func(cmd *cobra.Command, args []string) {
+		const location = "cleanerCmd->"
+
+		if !cleanerDryRun {
+			fmt.Println(`cleaner:
+		cleaner --dry-run: shows what the next cleaner check would do
+		cleaner restore [part names]: moves quarantined parts back to storage
+		cleaner run-now: makes cleaner check in the running node`)
+			return
+		}
+
+		err := loadStorageConfig()
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			log.Fatal(cleanerFatalMessage)
+		}
+
+		report, err := cleaner.Run(true)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			log.Fatal(cleanerFatalMessage)
+		}
+
+		printParts("would be marked as unused:", report.Marked)
+		printParts("would be quarantined:", report.Quarantined)
+		printParts("would be restored from quarantine:", report.Restored)
+		printParts("would be deleted:", report.Deleted)
+		printParts("storage providers whose files would be evicted:", report.Evicted)
+	},
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// loadStorageConfig unlocks account and sets storage paths from its config.
+func loadStorageConfig() error {
+	const location = "cmd.loadStorageConfig->"
+
+	nodeAccount, _, err := account.Unlock()
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	paths.SetConfigPath(nodeAccount.Address.String())
+
+	confFile, fileBytes, err := nodeFile.Read(paths.List().ConfigFile)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+	defer confFile.Close()
+
+	var nodeConfig nodeTypes.Config
+
+	err = json.Unmarshal(fileBytes, &nodeConfig)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if len(nodeConfig.StoragePaths) == 0 {
+		return logger.MarkLocation(location, errors.New("path to storage is not specified in config"))
+	}
+
+	paths.SetStoragePaths(nodeConfig.StoragePaths)
+	cleaner.SetConfig(nodeConfig.Cleaner)
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func printParts(title string, parts []string) {
+	fmt.Println(title, len(parts))
+
+	for _, part := range parts {
+		fmt.Println("\t" + part)
+	}
+}
+
+func init() {
+	cleanerCmd.Flags().BoolVar(&cleanerDryRun, "dry-run", false, "show what the next cleaner check would do without changing anything")
+	rootCmd.AddCommand(cleanerCmd)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/DeNetPRO/src/admin"
+	"github.com/DeNetPRO/src/cleaner"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/spf13/cobra"
+)
+
+// CleanerRestoreCmd is executed when "restore" flag is passed after "cleaner" flag and is used for moving
+// quarantined parts back to storage. If no part names are passed, all quarantined parts are restored.
+// Parts are restored by the running node, so its cleaner doesn't change the same state at once,
+// cleaner state is changed directly only when the node is not running.
+var cleanerRestoreCmd = &cobra.Command{
+	Use:   "restore [part names]",
+	Short: "moves quarantined parts back to storage",
+	Long:  "moves quarantined parts back to storage, all quarantined parts are restored if no part names are passed. Parts are restored by the running node through admin api, if admin api is disabled the node must be stopped first",
+	Run: func(cmd *cobra.Command, args []string) {
+		const location = "cleanerRestoreCmd->"
+
+		ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+		defer cancel()
+
+		restored, err := admin.NewClient(adminAddress).RestoreQuarantined(ctx, args)
+		if errors.Is(err, admin.ErrNotRunning) {
+			restored, err = restoreOffline(args)
+		}
+
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			log.Fatal(cleanerFatalMessage)
+		}
+
+		printParts("restored from quarantine:", restored)
+	},
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func restoreOffline(fileNames []string) ([]string, error) {
+	const location = "cmd.restoreOffline->"
+
+	err := loadStorageConfig()
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	restored, err := cleaner.Restore(fileNames)
+	if err != nil {
+		return restored, logger.MarkLocation(location, err)
+	}
+
+	return restored, nil
+}
+
+func init() {
+	cleanerCmd.AddCommand(cleanerRestoreCmd)
+}
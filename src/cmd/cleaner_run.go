@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"log"
+
+	"github.com/DeNetPRO/src/admin"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/spf13/cobra"
+)
+
+// CleanerRunCmd is executed when "run-now" flag is passed after "cleaner" flag and makes cleaner check
+// in the running node without waiting for the scheduled one.
+var cleanerRunCmd = &cobra.Command{
+	Use:   "run-now",
+	Short: "makes cleaner check in the running node",
+	Long:  "makes cleaner check in the running node without waiting for the scheduled one",
+	Run: func(cmd *cobra.Command, args []string) {
+		const location = "cleanerRunCmd->"
+
+		ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+		defer cancel()
+
+		report, err := admin.NewClient(adminAddress).RunCleaner(ctx)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			log.Fatal(err)
+		}
+
+		printParts("marked as unused:", report.Marked)
+		printParts("quarantined:", report.Quarantined)
+		printParts("restored from quarantine:", report.Restored)
+		printParts("deleted:", report.Deleted)
+		printParts("storage providers whose files were evicted:", report.Evicted)
+	},
+}
+
+func init() {
+	cleanerCmd.AddCommand(cleanerRunCmd)
+}
@@ -2,12 +2,11 @@ package cmd
 
 import (
 	"log"
+	"path/filepath"
 
 	"github.com/DeNetPRO/src/account"
-	blckChain "github.com/DeNetPRO/src/blockchain_provider"
-	"github.com/DeNetPRO/src/cleaner"
+	"github.com/DeNetPRO/src/logger"
 	"github.com/DeNetPRO/src/paths"
-	"github.com/DeNetPRO/src/rpcserver"
 	tstpkg "github.com/DeNetPRO/src/tst_pkg"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
@@ -23,19 +22,20 @@ var debug = &cobra.Command{
 
 		paths.Init()
 
-		addr, nodeConfig, err := account.Import()
+		addr, password, nodeConfig, err := account.Import()
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		go blckChain.StartMakingProofs(common.HexToAddress(addr), tstpkg.Data().Password, nodeConfig)
-		go cleaner.Start()
-
-		rpcserver.Start(tstpkg.TestConfig().HTTPPort)
+		err = logger.Init(nodeConfig.Logging, filepath.Join(paths.List().WorkDir, "logs"))
 		if err != nil {
-			log.Fatal(err)
+			logger.Log(err)
 		}
 
+		nodeConfig.HTTPPort = tstpkg.TestConfig().HTTPPort
+
+		runNode(common.HexToAddress(addr), password, nodeConfig)
+
 	},
 }
 
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/DeNetPRO/src/admin"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/spf13/cobra"
+)
+
+// ProofsCmd is executed when "proofs" flag is passed, its subcommands pause and resume proofs in the running node.
+var proofsCmd = &cobra.Command{
+	Use:   "proofs",
+	Short: "manages proofs of the running node",
+	Long:  "manages proofs of the running node",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(`proofs:
+		proofs pause: stops sending proofs until they are resumed
+		proofs resume: resumes sending proofs`)
+	},
+}
+
+var proofsPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "stops sending proofs until they are resumed",
+	Long:  "stops sending proofs until they are resumed, node keeps serving files",
+	Run: func(cmd *cobra.Command, args []string) {
+		const location = "proofsPauseCmd->"
+
+		ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+		defer cancel()
+
+		_, err := admin.NewClient(adminAddress).PauseProofs(ctx)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			log.Fatal(err)
+		}
+
+		fmt.Println("proofs are paused")
+	},
+}
+
+var proofsResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "resumes sending proofs",
+	Long:  "resumes sending proofs",
+	Run: func(cmd *cobra.Command, args []string) {
+		const location = "proofsResumeCmd->"
+
+		ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+		defer cancel()
+
+		_, err := admin.NewClient(adminAddress).ResumeProofs(ctx)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			log.Fatal(err)
+		}
+
+		fmt.Println("proofs are resumed")
+	},
+}
+
+func init() {
+	proofsCmd.AddCommand(proofsPauseCmd)
+	proofsCmd.AddCommand(proofsResumeCmd)
+	rootCmd.AddCommand(proofsCmd)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DeNetPRO/src/admin"
+	blckChain "github.com/DeNetPRO/src/blockchain_provider"
+	"github.com/DeNetPRO/src/cleaner"
+	"github.com/DeNetPRO/src/health"
+	ipWatcher "github.com/DeNetPRO/src/ip_watcher"
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/probe"
+	"github.com/DeNetPRO/src/reload"
+	"github.com/DeNetPRO/src/rpcserver"
+	"github.com/DeNetPRO/src/supervisor"
+	"github.com/DeNetPRO/src/telemetry"
+	"github.com/DeNetPRO/src/upnp"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// telemetry events that are still queued are sent for that long on exit
+const telemetryFlushTimeout = 10 * time.Second
+
+// runNode runs rpc server, cleaner, proofs, ip watcher and reachability probe (if password is set) until SIGINT/SIGTERM is received.
+// Failed workers are restarted. Config is reloaded when config file is changed or SIGHUP is received,
+// restarted workers get reloaded config.
+func runNode(nodeAddr common.Address, password string, nodeConfig nodeTypes.Config) {
+	const location = "cmd.runNode->"
+
+	reload.Init(nodeConfig)
+
+	workers := []supervisor.Worker{
+		{
+			Name: health.RPCServer,
+			Run: func(ctx context.Context) error {
+				return rpcserver.Start(ctx, reload.Current())
+			},
+		},
+		{
+			Name: health.Cleaner,
+			Run: func(ctx context.Context) error {
+				return cleaner.Start(ctx, reload.Current().Cleaner)
+			},
+		},
+		{
+			Name: health.Config,
+			Run:  reload.Watch,
+		},
+	}
+
+	if !nodeConfig.NAT.Disabled {
+		workers = append(workers, supervisor.Worker{
+			Name: health.NAT,
+			Run: func(ctx context.Context) error {
+				current := reload.Current()
+				return upnp.Run(ctx, current.NAT, current.HTTPPort)
+			},
+		})
+	}
+
+	if !nodeConfig.Admin.Disabled {
+		workers = append(workers, supervisor.Worker{
+			Name: health.Admin,
+			Run: func(ctx context.Context) error {
+				return admin.Serve(ctx, nodeConfig.Admin)
+			},
+		})
+	}
+
+	if password != "" {
+		workers = append(workers, supervisor.Worker{
+			Name: health.Proofs,
+			Run: func(ctx context.Context) error {
+				return blckChain.StartMakingProofs(ctx, nodeAddr, password, reload.Current())
+			},
+		}, supervisor.Worker{
+			Name: health.IPWatcher,
+			Run: func(ctx context.Context) error {
+				return ipWatcher.Start(ctx, nodeAddr, password)
+			},
+		}, supervisor.Worker{
+			Name: health.Probe,
+			Run: func(ctx context.Context) error {
+				return probe.Start(ctx, nodeAddr)
+			},
+		})
+	}
+
+	opts := supervisor.Options{
+		Reload: func() {
+			err := reload.Reload()
+			if err != nil {
+				logger.Log(logger.MarkLocation(location, err))
+			}
+		},
+	}
+
+	err := supervisor.Run(context.Background(), opts, workers...)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryFlushTimeout)
+	defer cancel()
+
+	err = telemetry.Flush(ctx)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+
+	fmt.Println("node stopped")
+}
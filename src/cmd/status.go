@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/DeNetPRO/src/admin"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/spf13/cobra"
+)
+
+const adminRequestTimeout = time.Minute
+
+var adminAddress string
+
+// StatusCmd is executed when "status" flag is passed and shows status of the running node.
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "shows status of the running node",
+	Long:  "shows status of the running node",
+	Run: func(cmd *cobra.Command, args []string) {
+		const location = "statusCmd->"
+
+		ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+		defer cancel()
+
+		status, err := admin.NewClient(adminAddress).Status(ctx)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			log.Fatal(err)
+		}
+
+		printStatus(status)
+	},
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func printStatus(status admin.Status) {
+	fmt.Println("account:", status.Account)
+	fmt.Println("network:", status.Network, "registered:", status.Registered)
+	fmt.Println("alive:", status.Alive, "ready:", status.Ready)
+	fmt.Println("proofs paused:", status.ProofsPaused)
+
+	if status.LastProof != nil {
+		fmt.Println("last proof:", status.LastProof.Tx, status.LastProof.Status, time.Unix(status.LastProof.Time, 0).Format(time.RFC3339))
+	}
+
+	if status.RPCEndpoint.OK {
+		fmt.Println("rpc endpoint: ok, last block:", status.LastBlock)
+	} else {
+		fmt.Println("rpc endpoint: not reachable", status.RPCEndpoint.Error)
+	}
+
+	if status.PortMapping.Mapped {
+		fmt.Println("port mapping:", status.PortMapping.Method, status.PortMapping.ExternalAddress)
+	} else {
+		fmt.Println("port mapping: not active", status.PortMapping.Error)
+	}
+
+	fmt.Println("storage reserved:", formatSize(status.StorageReserved), "of", formatSize(status.StorageLimit))
+	fmt.Println("free space:", formatSize(int64(status.FreeSpace)))
+
+	names := make([]string, 0, len(status.Workers))
+	for name := range status.Workers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Println("workers:")
+
+	for _, name := range names {
+		worker := status.Workers[name]
+
+		if worker.Error != "" {
+			fmt.Printf("\t%s alive: %v, error: %s\n", name, worker.Alive, worker.Error)
+			continue
+		}
+
+		fmt.Printf("\t%s alive: %v\n", name, worker.Alive)
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.2f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&adminAddress, "admin", "", "admin api address of the running node, unix socket in work dir is used by default")
+	rootCmd.AddCommand(statusCmd)
+}
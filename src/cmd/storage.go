@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/DeNetPRO/src/admin"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/spf13/cobra"
+)
+
+// StorageCmd is executed when "storage" flag is passed, its subcommands show storage info of the running node.
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "shows storage info of the running node",
+	Long:  "shows storage info of the running node",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(`storage:
+		storage usage: shows space used by each network and storage provider`)
+	},
+}
+
+var storageUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "shows space used by each network and storage provider",
+	Long:  "shows space used by each network and storage provider",
+	Run: func(cmd *cobra.Command, args []string) {
+		const location = "storageUsageCmd->"
+
+		ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+		defer cancel()
+
+		usage, err := admin.NewClient(adminAddress).StorageUsage(ctx)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			log.Fatal(err)
+		}
+
+		fmt.Println("limit:", formatSize(usage.Limit))
+		fmt.Println("reserved:", formatSize(usage.Reserved))
+		fmt.Println("free space:", formatSize(int64(usage.Free)))
+
+		networkNames := make([]string, 0, len(usage.Networks))
+		for network := range usage.Networks {
+			networkNames = append(networkNames, network)
+		}
+
+		sort.Strings(networkNames)
+
+		for _, network := range networkNames {
+			netUsage := usage.Networks[network]
+
+			fmt.Println(network, "used:", formatSize(netUsage.Used))
+
+			spAddresses := make([]string, 0, len(netUsage.StorageProviders))
+			for spAddress := range netUsage.StorageProviders {
+				spAddresses = append(spAddresses, spAddress)
+			}
+
+			sort.Strings(spAddresses)
+
+			for _, spAddress := range spAddresses {
+				fmt.Println("\t"+spAddress, formatSize(netUsage.StorageProviders[spAddress]))
+			}
+		}
+	},
+}
+
+func init() {
+	storageCmd.AddCommand(storageUsageCmd)
+	rootCmd.AddCommand(storageCmd)
+}
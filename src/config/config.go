@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,13 +10,17 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/DeNetPRO/src/networks"
 	"github.com/ricochet2200/go-disk-usage/du"
 
 	"github.com/DeNetPRO/src/logger"
+	nodeFile "github.com/DeNetPRO/src/node_file"
 	nodeTypes "github.com/DeNetPRO/src/node_types"
 	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/telemetry"
 
 	termEmul "github.com/DeNetPRO/src/term_emul"
 	tstpkg "github.com/DeNetPRO/src/tst_pkg"
@@ -46,6 +51,42 @@ var partiallyReservedIPs = map[string]int{
 
 var RPC string
 
+// mutex guards read-modify-write of config file, see Update.
+var mutex sync.Mutex
+
+// DefaultCleanerConfig keeps unreferenced parts for two hours before quarantine and for a day in quarantine.
+// Files of storage providers with zero balance are kept for a week.
+var DefaultCleanerConfig = nodeTypes.CleanerConfig{
+	GracePeriod:         60 * 60 * 2,
+	QuarantinePeriod:    60 * 60 * 24,
+	EvictionGracePeriod: 60 * 60 * 24 * 7,
+	ProtectedSPs:        []string{},
+}
+
+// DefaultLimitsConfig allows 20 requests per second from one address and 10 requests per second for one storage provider.
+var DefaultLimitsConfig = nodeTypes.LimitsConfig{
+	IPRate:                20,
+	IPBurst:               40,
+	SpRate:                10,
+	SpBurst:               20,
+	MaxMessageSize:        4 * 1024 * 1024,
+	MaxStreamSize:         1024 * 1024 * 1024,
+	MaxConcurrentStreams:  100,
+	MaxConcurrentRequests: 500,
+	IdleTimeout:           60,
+}
+
+// DefaultIPWatcherConfig checks public ip every 5 minutes and updates node info at most once an hour.
+// Echo services are not set, they should be chosen by node owner.
+var DefaultIPWatcherConfig = nodeTypes.IPWatcherConfig{
+	EchoServices:      []string{},
+	CheckInterval:     60 * 5,
+	MinUpdateInterval: 60 * 60,
+}
+
+// DefaultHTTPAddress is local, so metrics and health endpoints are not exposed to the network unless address is changed.
+const DefaultHTTPAddress = "127.0.0.1:9477"
+
 func Stats() Statuses {
 	return stats
 }
@@ -69,6 +110,28 @@ func Create(address string) (nodeTypes.Config, error) {
 			StoragePaths:         []string{},
 			SendBugReports:       true,
 			RegisteredInNetworks: map[string]bool{},
+			Cleaner:              DefaultCleanerConfig,
+			Quotas: nodeTypes.QuotaConfig{
+				StorageProviders: map[string]nodeTypes.Quota{},
+				Networks:         map[string]nodeTypes.Quota{},
+			},
+			Gateways: nodeTypes.GatewayConfig{
+				Allowed: []string{},
+			},
+			Limits: DefaultLimitsConfig,
+			Bandwidth: nodeTypes.BandwidthConfig{
+				Schedules: []nodeTypes.BandwidthSchedule{},
+			},
+			Metrics: nodeTypes.MetricsConfig{
+				Address: DefaultHTTPAddress,
+			},
+			Health: nodeTypes.HealthConfig{
+				Enabled: true,
+				Address: DefaultHTTPAddress,
+			},
+			Logging:   logger.DefaultConfig,
+			Telemetry: telemetry.DefaultConfig,
+			IPWatcher: DefaultIPWatcherConfig,
 			RPC: map[string]string{"kovan": "https://kovan.infura.io/v3/45b81222fded4427b3a6589e0396c596",
 				"polygon": "https://polygon-rpc.com"},
 		}
@@ -92,12 +155,12 @@ func Create(address string) (nodeTypes.Config, error) {
 			return nodeConfig, logger.MarkLocation(location, err)
 		}
 
-		if upnp.InternetDevice != nil {
-			ip, err := upnp.InternetDevice.PublicIP()
-			if err != nil {
-				return nodeConfig, logger.MarkLocation(location, err)
-			}
-			nodeConfig.IpAddress = ip
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ip, err := upnp.ExternalIP(ctx)
+		cancel()
+
+		if err == nil {
+			nodeConfig.IpAddress = ip.String()
 			fmt.Println("Your public IP address", ip, "is added to config")
 		} else {
 			fmt.Println("\nPlease enter your public ip address")
@@ -588,10 +651,8 @@ func SwitchReports(nodeConfig *nodeTypes.Config, state string) error {
 
 		if agree == "y" {
 			nodeConfig.SendBugReports = true
-			logger.SendReports = true
 		} else {
 			nodeConfig.SendBugReports = false
-			logger.SendReports = false
 		}
 
 		break
@@ -602,6 +663,27 @@ func SwitchReports(nodeConfig *nodeTypes.Config, state string) error {
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
+// Read returns config of the logged in account.
+func Read() (nodeTypes.Config, error) {
+	const location = "config.Read->"
+
+	var nodeConfig nodeTypes.Config
+
+	fileBytes, err := os.ReadFile(paths.List().ConfigFile)
+	if err != nil {
+		return nodeConfig, logger.MarkLocation(location, err)
+	}
+
+	err = json.Unmarshal(fileBytes, &nodeConfig)
+	if err != nil {
+		return nodeConfig, logger.MarkLocation(location, err)
+	}
+
+	return nodeConfig, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
 // Saves the configuration file
 func Save(configFile *os.File, Config nodeTypes.Config) error {
 	confJSON, err := json.Marshal(Config)
@@ -636,6 +718,43 @@ func Save(configFile *os.File, Config nodeTypes.Config) error {
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
+// Update reads config file, changes it with update and saves it. Config file is changed concurrently
+// by rpc server, cleaner and ip watcher of the running node, so they must change it only with Update.
+// Config file is not saved if update returns error.
+func Update(update func(nodeConfig *nodeTypes.Config) error) error {
+	const location = "config.Update->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	confFile, fileBytes, err := nodeFile.Read(paths.List().ConfigFile)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+	defer confFile.Close()
+
+	var nodeConfig nodeTypes.Config
+
+	err = json.Unmarshal(fileBytes, &nodeConfig)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = update(&nodeConfig)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = Save(confFile, nodeConfig)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
 // Return nodes available space in GB
 func getAvailableSpace(path string) (int, error) {
 	const location = "config.GetAvailableSpace ->"
@@ -1,11 +1,23 @@
 package errs
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
+// Domain is set in error details of rpc errors.
+const Domain = "denet.node"
+
+// retryDelay is suggested to clients that exceeded rate limit.
+const retryDelay = time.Second
+
 var errorList = nodeTypes.ErrList{
 	FileName:      errors.New("wrong file"),
 	Network:       errors.New("unsupported network"),
@@ -19,6 +31,48 @@ var errorList = nodeTypes.ErrList{
 	Internal:      errors.New("node internal error"),
 	Argument:      errors.New("invalid argument"),
 	StorageSystem: errors.New("storage filesystem not found"),
+	FsOutdated:    errors.New("fs info is outdated"),
+	FileSize:      errors.New("file size limit exceeded"),
+	Quota:         errors.New("storage quota exceeded"),
+	Nonce:         errors.New("invalid or expired nonce"),
+	Gateway:       errors.New("gateway is not authorized"),
+	RateLimit:     errors.New("too many requests"),
+	StreamSize:    errors.New("stream size limit exceeded"),
+	IdleStream:    errors.New("stream is idle for too long"),
+	NotFound:      errors.New("file not found"),
+	Traffic:       errors.New("paid traffic exceeded"),
+}
+
+type rpcStatus struct {
+	err    error
+	code   codes.Code
+	reason string
+}
+
+// statusList maps errors from the list to rpc codes, reason is passed to clients in error details.
+var statusList = []rpcStatus{
+	{errorList.FileName, codes.InvalidArgument, "FILE_NAME"},
+	{errorList.Network, codes.InvalidArgument, "NETWORK"},
+	{errorList.FileSave, codes.Internal, "FILE_SAVE"},
+	{errorList.FsUpdate, codes.Internal, "FS_UPDATE"},
+	{errorList.Signature, codes.Unauthenticated, "SIGNATURE"},
+	{errorList.FileCheck, codes.Internal, "FILE_CHECK"},
+	{errorList.Multipart, codes.InvalidArgument, "MULTIPART"},
+	{errorList.SpaceCheck, codes.Internal, "SPACE_CHECK"},
+	{errorList.Space, codes.ResourceExhausted, "SPACE"},
+	{errorList.Internal, codes.Internal, "INTERNAL"},
+	{errorList.Argument, codes.InvalidArgument, "ARGUMENT"},
+	{errorList.StorageSystem, codes.NotFound, "STORAGE_SYSTEM"},
+	{errorList.FsOutdated, codes.FailedPrecondition, "FS_OUTDATED"},
+	{errorList.FileSize, codes.InvalidArgument, "FILE_SIZE"},
+	{errorList.Quota, codes.ResourceExhausted, "QUOTA"},
+	{errorList.Nonce, codes.Unauthenticated, "NONCE"},
+	{errorList.Gateway, codes.PermissionDenied, "GATEWAY"},
+	{errorList.RateLimit, codes.ResourceExhausted, "RATE_LIMIT"},
+	{errorList.StreamSize, codes.ResourceExhausted, "STREAM_SIZE"},
+	{errorList.IdleStream, codes.DeadlineExceeded, "IDLE_STREAM"},
+	{errorList.NotFound, codes.NotFound, "NOT_FOUND"},
+	{errorList.Traffic, codes.ResourceExhausted, "TRAFFIC"},
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -26,3 +80,55 @@ var errorList = nodeTypes.ErrList{
 func List() nodeTypes.ErrList {
 	return errorList
 }
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Status converts error returned by rpc handler to rpc status. Errors from the list get matching code
+// with reason in error details, rpc statuses are kept as is. Other errors are replaced with internal error,
+// so local details like paths don't leak to clients, known is false for them.
+func Status(err error) (st *status.Status, known bool) {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		return grpcErr.GRPCStatus(), true
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return status.New(codes.Canceled, context.Canceled.Error()), true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.New(codes.DeadlineExceeded, context.DeadlineExceeded.Error()), true
+	}
+
+	for _, s := range statusList {
+		if errors.Is(err, s.err) {
+			return withDetails(s), true
+		}
+	}
+
+	return withDetails(rpcStatus{errorList.Internal, codes.Internal, "INTERNAL"}), false
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func withDetails(s rpcStatus) *status.Status {
+	st := status.New(s.code, s.err.Error())
+
+	stWithDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: s.reason, Domain: Domain})
+	if err != nil {
+		return st
+	}
+
+	if s.err != errorList.RateLimit {
+		return stWithDetails
+	}
+
+	stWithRetry, err := stWithDetails.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	if err != nil {
+		return stWithDetails
+	}
+
+	return stWithRetry
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
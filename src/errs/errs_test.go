@@ -0,0 +1,46 @@
+package errs_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatus(t *testing.T) {
+	st, known := errs.Status(logger.MarkLocation("test->", errs.List().Space))
+	require.True(t, known)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Equal(t, errs.List().Space.Error(), st.Message())
+
+	details := st.Details()
+	require.Len(t, details, 1)
+
+	info, ok := details[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, "SPACE", info.Reason)
+	require.Equal(t, errs.Domain, info.Domain)
+
+	st, known = errs.Status(errs.List().Signature)
+	require.True(t, known)
+	require.Equal(t, codes.Unauthenticated, st.Code())
+
+	st, known = errs.Status(status.Error(codes.Unavailable, "unavailable"))
+	require.True(t, known)
+	require.Equal(t, codes.Unavailable, st.Code())
+}
+
+func TestStatusHidesUnknownErrors(t *testing.T) {
+	_, err := os.Open("/nonexistent/storage/part")
+	require.Error(t, err)
+
+	st, known := errs.Status(err)
+	require.False(t, known)
+	require.Equal(t, codes.Internal, st.Code())
+	require.NotContains(t, st.Message(), "/nonexistent")
+}
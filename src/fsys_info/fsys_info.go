@@ -1,39 +1,58 @@
 package fsysinfo
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
-	"mime/multipart"
+	"math"
 	"os"
 	"path/filepath"
 	"sync"
 
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/hash"
 	"github.com/DeNetPRO/src/logger"
 	"github.com/DeNetPRO/src/pb"
 
-	nodeFile "github.com/DeNetPRO/src/node_file"
 	nodeTypes "github.com/DeNetPRO/src/node_types"
+	spFiles "github.com/DeNetPRO/src/sp_files"
 
 	"github.com/DeNetPRO/src/paths"
 )
 
-var mutex sync.Mutex
+const (
+	legacySpFsFilename = "sp_fs.json"
+	spFsMagic          = "DNFS"
+	spFsVersion        = 2
+	leafSize           = 32
+	historyDepth       = 8 // count of fs snapshots kept per storage provider
+
+	MaxBackupSize = 32 * 1024 * 1024 // max size of storage provider's filesystem backup in bytes
+
+	backupHeaderSize  = 4         // size of backup info length prefix
+	maxBackupInfoSize = 64 * 1024 // max size of backup info stored in front of backup data
+)
+
+var (
+	mutex       sync.Mutex
+	backupMutex sync.Mutex
+)
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
+// Save adds storage provider's fs info to the head of fs history if its nonce is not lower than the latest one.
+// Previous snapshots are kept, because smart contract may still hold one of them.
+// Only tree leaves are written to disk, upper levels are rebuilt on read.
 func Save(fsInfo *pb.FsInfo, fsTree [][][]byte) error {
 	const location = "fsys_info.Save->"
 
-	pathToSpFiles := filepath.Join(paths.List().Storages[0], fsInfo.Network, fsInfo.SpAddress)
-
-	pathToSpFs := filepath.Join(pathToSpFiles, paths.List().SpFsFilename)
-
-	stat, err := os.Stat(pathToSpFs)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return logger.MarkLocation(location, errors.New("no files of "+fsInfo.SpAddress))
-	}
+	pathToSpFs := spFsPath(fsInfo.Network, fsInfo.SpAddress)
 
 	mutex.Lock()
 	defer mutex.Unlock()
@@ -42,83 +61,692 @@ func Save(fsInfo *pb.FsInfo, fsTree [][][]byte) error {
 		Nonce:        fsInfo.Nonce,
 		Storage:      fsInfo.Storage,
 		SignedFsInfo: fsInfo.Signature,
+		Root:         fsTree[len(fsTree)-1][0],
 		Tree:         fsTree,
 	}
 
-	if stat == nil {
+	history, err := read(pathToSpFs)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return logger.MarkLocation(location, err)
+	}
+
+	if len(history) != 0 && newFsInfo.Nonce < history[0].Nonce {
+		return logger.MarkLocation(location, fmt.Errorf("%v: %w", fsInfo.SpAddress, errs.List().FsOutdated))
+	}
+
+	if len(history) != 0 && newFsInfo.Nonce == history[0].Nonce {
+		history = history[1:]
+	}
+
+	newHistory := make([]nodeTypes.StorageProviderData, 0, historyDepth)
+	newHistory = append(newHistory, newFsInfo)
+
+	for _, spFs := range history {
+		if len(newHistory) == historyDepth {
+			break
+		}
+
+		newHistory = append(newHistory, spFs)
+	}
+
+	fsBytes, err := encode(newHistory)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = writeAtomic(pathToSpFs, fsBytes)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	cache.put(pathToSpFs, newHistory)
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Get returns the latest storage provider's fs info with the whole merkle tree.
+// Recently used trees are served from memory.
+func Get(network, spAddress string) (nodeTypes.StorageProviderData, error) {
+	const location = "fsys_info.Get->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	spFs, err := snapshot(spFsPath(network, spAddress), nil)
+	if err != nil {
+		return spFs, logger.MarkLocation(location, err)
+	}
+
+	return spFs, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Snapshot returns storage provider's fs info with passed root hash and the whole merkle tree.
+func Snapshot(network, spAddress string, root []byte) (nodeTypes.StorageProviderData, error) {
+	const location = "fsys_info.Snapshot->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	spFs, err := snapshot(spFsPath(network, spAddress), root)
+	if err != nil {
+		return spFs, logger.MarkLocation(location, err)
+	}
+
+	return spFs, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// History returns stored storage provider's fs snapshots from newest to oldest. Trees are not included.
+func History(network, spAddress string) ([]nodeTypes.StorageProviderData, error) {
+	const location = "fsys_info.History->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	history, err := read(spFsPath(network, spAddress))
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	headers := make([]nodeTypes.StorageProviderData, 0, len(history))
+
+	for _, spFs := range history {
+		spFs.Tree = nil
+		headers = append(headers, spFs)
+	}
+
+	return headers, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Prune removes snapshots that are older than the passed nonce, when newer snapshot is confirmed by smart contract.
+func Prune(network, spAddress string, nonce uint32) error {
+	const location = "fsys_info.Prune->"
+
+	pathToSpFs := spFsPath(network, spAddress)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	history, err := read(pathToSpFs)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	keep := len(history)
+
+	for keep > 1 && history[keep-1].Nonce < nonce {
+		keep--
+	}
+
+	if keep == len(history) {
+		return nil
+	}
+
+	history = history[:keep]
+
+	fsBytes, err := encode(history)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = writeAtomic(pathToSpFs, fsBytes)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
 
-		file, err := os.Create(pathToSpFs)
+	cache.put(pathToSpFs, history)
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Leaves returns hex encoded hashes of all parts referenced by stored fs snapshots without building upper tree levels.
+func Leaves(network, spAddress string) (map[string]bool, error) {
+	const location = "fsys_info.Leaves->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	history, err := read(spFsPath(network, spAddress))
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	return leaves(history), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// PeekLeaves works as Leaves, but legacy fs info isn't converted, so nothing is changed on disk.
+func PeekLeaves(network, spAddress string) (map[string]bool, error) {
+	const location = "fsys_info.PeekLeaves->"
+
+	pathToSpFs := spFsPath(network, spAddress)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	history, cached := cache.get(pathToSpFs)
+	if cached {
+		return leaves(history), nil
+	}
+
+	fsBytes, err := os.ReadFile(pathToSpFs)
+	if errors.Is(err, os.ErrNotExist) {
+		history, err = readLegacy(pathToSpFs)
+		if err != nil {
+			return nil, logger.MarkLocation(location, err)
+		}
+
+		return leaves(history), nil
+	}
+
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	history, err = decode(fsBytes)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	return leaves(history), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func leaves(history []nodeTypes.StorageProviderData) map[string]bool {
+	leaves := make(map[string]bool, len(history[0].Tree[0]))
+
+	for _, spFs := range history {
+		for _, leaf := range spFs.Tree[0] {
+			leaves[hex.EncodeToString(leaf)] = true
+		}
+	}
+
+	return leaves
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Remove deletes storage provider's fs info from disk and memory.
+func Remove(network, spAddress string) error {
+	const location = "fsys_info.Remove->"
+
+	pathToSpFs := spFsPath(network, spAddress)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	cache.remove(pathToSpFs)
+
+	os.Remove(filepath.Join(filepath.Dir(pathToSpFs), legacySpFsFilename))
+
+	err := os.Remove(pathToSpFs)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Returns snapshot with passed root, or the latest one if root is nil, and builds its merkle tree if needed.
+func snapshot(pathToSpFs string, root []byte) (nodeTypes.StorageProviderData, error) {
+	const location = "fsys_info.snapshot->"
+
+	var spFs nodeTypes.StorageProviderData
+
+	history, err := read(pathToSpFs)
+	if err != nil {
+		return spFs, logger.MarkLocation(location, err)
+	}
+
+	indx := 0
+
+	if root != nil {
+		indx = -1
+
+		for i, s := range history {
+			if bytes.Equal(s.Root, root) {
+				indx = i
+				break
+			}
+		}
+
+		if indx == -1 {
+			return spFs, logger.MarkLocation(location, errors.New("fs snapshot is not found"))
+		}
+	}
+
+	spFs = history[indx]
+
+	if len(spFs.Tree) == 1 {
+		spFs.Tree, err = hash.BuildTree(spFs.Tree[0])
 		if err != nil {
-			return logger.MarkLocation(location, err)
+			return spFs, logger.MarkLocation(location, err)
 		}
-		defer file.Close()
 
-		err = nodeFile.Write(file, newFsInfo)
+		history[indx] = spFs
+		cache.put(pathToSpFs, history)
+	}
+
+	return spFs, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Reads fs history from cache or disk. Legacy json files are converted on first read.
+// Snapshots that weren't requested through snapshot func contain only base tree level.
+func read(pathToSpFs string) ([]nodeTypes.StorageProviderData, error) {
+	const location = "fsys_info.read->"
+
+	cached, ok := cache.get(pathToSpFs)
+	if ok {
+		return cached, nil
+	}
+
+	fsBytes, err := os.ReadFile(pathToSpFs)
+	if errors.Is(err, os.ErrNotExist) {
+		history, err := migrate(pathToSpFs)
 		if err != nil {
-			return logger.MarkLocation(location, err)
+			return nil, logger.MarkLocation(location, err)
 		}
 
-		file.Sync()
+		cache.put(pathToSpFs, history)
 
-		return nil
+		return history, nil
+	}
 
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
 	}
 
-	file, bytes, err := nodeFile.Read(pathToSpFs)
+	history, err := decode(fsBytes)
 	if err != nil {
-		return logger.MarkLocation(location, err)
+		return nil, logger.MarkLocation(location, err)
 	}
 
-	defer file.Close()
+	cache.put(pathToSpFs, history)
+
+	return history, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-	var previousFsInfo nodeTypes.StorageProviderData
+// Converts fs info stored as json to binary format.
+func migrate(pathToSpFs string) ([]nodeTypes.StorageProviderData, error) {
+	const location = "fsys_info.migrate->"
 
-	err = json.Unmarshal(bytes, &previousFsInfo)
+	history, err := readLegacy(pathToSpFs)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	fsBytes, err := encode(history)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	err = writeAtomic(pathToSpFs, fsBytes)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	err = os.Remove(filepath.Join(filepath.Dir(pathToSpFs), legacySpFsFilename))
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+
+	return history, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Reads fs info stored as json next to binary fs info path.
+func readLegacy(pathToSpFs string) ([]nodeTypes.StorageProviderData, error) {
+	const location = "fsys_info.readLegacy->"
+
+	var spFs nodeTypes.StorageProviderData
+
+	fsBytes, err := os.ReadFile(filepath.Join(filepath.Dir(pathToSpFs), legacySpFsFilename))
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	err = json.Unmarshal(fsBytes, &spFs)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	if len(spFs.Tree) == 0 || len(spFs.Tree[0]) == 0 {
+		return nil, logger.MarkLocation(location, errors.New("fs tree is empty"))
+	}
+
+	spFs.Root = spFs.Tree[len(spFs.Tree)-1][0]
+
+	return []nodeTypes.StorageProviderData{spFs}, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Encodes fs history as: magic | version | snapshots count | snapshots | crc32,
+// where every snapshot is: nonce | storage | root | signature length | signature | leaves count | leaves.
+// Fields that can't be decoded back are rejected, so fs info on disk stays readable.
+func encode(history []nodeTypes.StorageProviderData) ([]byte, error) {
+	const location = "fsys_info.encode->"
+
+	if len(history) > math.MaxUint8 {
+		return nil, logger.MarkLocation(location, errors.New("too many fs snapshots"))
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(spFsMagic)
+	buf.WriteByte(spFsVersion)
+	buf.WriteByte(uint8(len(history)))
+
+	for _, spFs := range history {
+		if len(spFs.Tree) == 0 {
+			return nil, logger.MarkLocation(location, errors.New("fs tree is empty"))
+		}
+
+		leaves := spFs.Tree[0]
+
+		if len(spFs.Root) != leafSize {
+			return nil, logger.MarkLocation(location, fmt.Errorf("fs root size is %d bytes", len(spFs.Root)))
+		}
+
+		if len(spFs.SignedFsInfo) > math.MaxUint16 {
+			return nil, logger.MarkLocation(location, errors.New("fs info signature is too long"))
+		}
+
+		if uint64(len(leaves)) > math.MaxUint32 {
+			return nil, logger.MarkLocation(location, errors.New("too many fs tree leaves"))
+		}
+
+		buf.Grow(46 + len(spFs.SignedFsInfo) + len(leaves)*leafSize)
+
+		binary.Write(&buf, binary.BigEndian, spFs.Nonce)
+		binary.Write(&buf, binary.BigEndian, spFs.Storage)
+		buf.Write(spFs.Root)
+		binary.Write(&buf, binary.BigEndian, uint16(len(spFs.SignedFsInfo)))
+		buf.WriteString(spFs.SignedFsInfo)
+		binary.Write(&buf, binary.BigEndian, uint32(len(leaves)))
+
+		for _, leaf := range leaves {
+			if len(leaf) != leafSize {
+				return nil, logger.MarkLocation(location, fmt.Errorf("fs tree leaf size is %d bytes", len(leaf)))
+			}
+
+			buf.Write(leaf)
+		}
+	}
+
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+
+	return buf.Bytes(), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Decodes binary fs history. Returned trees contain only base level.
+// Files of the first version contain a single snapshot without root hash, so the root is calculated.
+func decode(fsBytes []byte) ([]nodeTypes.StorageProviderData, error) {
+	const location = "fsys_info.decode->"
+
+	if len(fsBytes) < len(spFsMagic)+2+4 {
+		return nil, logger.MarkLocation(location, errors.New("fs info is truncated"))
+	}
+
+	body, checksum := fsBytes[:len(fsBytes)-4], binary.BigEndian.Uint32(fsBytes[len(fsBytes)-4:])
+
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, logger.MarkLocation(location, errors.New("fs info checksum mismatch"))
+	}
+
+	if string(body[:len(spFsMagic)]) != spFsMagic {
+		return nil, logger.MarkLocation(location, errors.New("unknown fs info format"))
+	}
+
+	version := body[len(spFsMagic)]
+	body = body[len(spFsMagic)+1:]
+
+	snapshotsCount := 1
+	withRoot := false
+
+	switch version {
+	case 1:
+	case 2:
+		snapshotsCount = int(body[0])
+		body = body[1:]
+		withRoot = true
+	default:
+		return nil, logger.MarkLocation(location, fmt.Errorf("unsupported fs info version %d", version))
+	}
+
+	history := make([]nodeTypes.StorageProviderData, 0, snapshotsCount)
+
+	for i := 0; i < snapshotsCount; i++ {
+		spFs, rest, err := decodeSnapshot(body, withRoot)
+		if err != nil {
+			return nil, logger.MarkLocation(location, err)
+		}
+
+		if !withRoot {
+			tree, err := hash.BuildTree(spFs.Tree[0])
+			if err != nil {
+				return nil, logger.MarkLocation(location, err)
+			}
+
+			spFs.Root = tree[len(tree)-1][0]
+		}
+
+		history = append(history, spFs)
+		body = rest
+	}
+
+	if len(body) != 0 || len(history) == 0 {
+		return nil, logger.MarkLocation(location, errors.New("fs info is corrupted"))
+	}
+
+	return history, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Decodes a single snapshot and returns the rest of passed bytes.
+func decodeSnapshot(body []byte, withRoot bool) (nodeTypes.StorageProviderData, []byte, error) {
+	var spFs nodeTypes.StorageProviderData
+
+	errTruncated := errors.New("fs info is truncated")
+
+	headerLen := 4 + 4 + 2
+
+	if withRoot {
+		headerLen += leafSize
+	}
+
+	if len(body) < headerLen {
+		return spFs, nil, errTruncated
+	}
+
+	spFs.Nonce = binary.BigEndian.Uint32(body)
+	spFs.Storage = binary.BigEndian.Uint32(body[4:])
+	body = body[8:]
+
+	if withRoot {
+		spFs.Root = body[:leafSize:leafSize]
+		body = body[leafSize:]
+	}
+
+	sigLen := int(binary.BigEndian.Uint16(body))
+	body = body[2:]
+
+	if len(body) < sigLen+4 {
+		return spFs, nil, errTruncated
+	}
+
+	spFs.SignedFsInfo = string(body[:sigLen])
+	body = body[sigLen:]
+
+	leavesCount := int(binary.BigEndian.Uint32(body))
+	body = body[4:]
+
+	if leavesCount == 0 || len(body) < leavesCount*leafSize {
+		return spFs, nil, errors.New("fs info leaves are corrupted")
+	}
+
+	leaves := make([][]byte, 0, leavesCount)
+
+	for i := 0; i < leavesCount; i++ {
+		start := i * leafSize
+		leaves = append(leaves, body[start:start+leafSize:start+leafSize]) // capacity is limited so appending to a leaf never overwrites the next one
+	}
+
+	spFs.Tree = [][][]byte{leaves}
+
+	return spFs, body[leavesCount*leafSize:], nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Writes data to a temporary file and renames it, so readers never see partially written fs info.
+func writeAtomic(path string, data []byte) error {
+	const location = "fsys_info.writeAtomic->"
+
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
 	if err != nil {
 		return logger.MarkLocation(location, err)
 	}
 
-	if newFsInfo.Nonce < previousFsInfo.Nonce {
-		return logger.MarkLocation(location, fmt.Errorf("%v fs info is up to date", fsInfo.SpAddress))
+	_, err = file.Write(data)
+	if err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return logger.MarkLocation(location, err)
 	}
 
-	err = nodeFile.Write(file, newFsInfo)
+	err = file.Sync()
 	if err != nil {
+		file.Close()
+		os.Remove(tmpPath)
 		return logger.MarkLocation(location, err)
 	}
 
-	file.Sync()
+	file.Close()
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		os.Remove(tmpPath)
+		return logger.MarkLocation(location, err)
+	}
 
 	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
+func spFsPath(network, spAddress string) string {
+	return filepath.Join(paths.List().Storages[0], network, spAddress, paths.List().SpFsFilename)
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-// Returns storage provider filesystem path
-func BackUpSPFsys(spAddress string, fileSystemHeader *multipart.FileHeader) error {
-	const location = "spFiles.UpdateStorageFilesystem"
+// BackUpSPFsys stores storage provider's encrypted filesystem if its version is greater than the stored one.
+// Backup info and data are kept in one file under SysDir/network/spAddress, which is written to a temporary file
+// and replaces the previous one only if its size and hash match passed info.
+func BackUpSPFsys(network, spAddress string, info nodeTypes.FsBackupInfo, fileSystem io.Reader) error {
+	const location = "fsys_info.BackUpSPFsys->"
 
-	mutex.Lock()
-	defer mutex.Unlock()
+	err := paths.CheckAddress(spAddress)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if info.Size > MaxBackupSize {
+		return logger.MarkLocation(location, errs.List().FileSize)
+	}
+
+	backupHash, err := hex.DecodeString(info.Hash)
+	if err != nil || len(backupHash) != sha256.Size {
+		return logger.MarkLocation(location, fmt.Errorf("%w: bad backup hash", errs.List().Argument))
+	}
 
-	fileSystem, err := fileSystemHeader.Open()
+	infoJSON, err := json.Marshal(info)
 	if err != nil {
 		return logger.MarkLocation(location, err)
 	}
 
-	defer fileSystem.Close()
+	backupPath := filepath.Join(paths.List().SysDir, network, spAddress)
 
-	path := filepath.Join(paths.List().SysDir, spAddress)
+	err = os.MkdirAll(filepath.Dir(backupPath), 0700)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
 
-	file, err := os.Create(path)
+	file, err := os.CreateTemp(filepath.Dir(backupPath), spAddress+"-*.tmp")
 	if err != nil {
 		return logger.MarkLocation(location, err)
 	}
 
+	defer os.Remove(file.Name())
 	defer file.Close()
 
-	_, err = io.Copy(file, fileSystem)
+	header := make([]byte, backupHeaderSize)
+	binary.BigEndian.PutUint32(header, uint32(len(infoJSON)))
+
+	_, err = file.Write(append(header, infoJSON...))
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	hasher := sha256.New()
+
+	written, err := io.Copy(io.MultiWriter(file, hasher), io.LimitReader(fileSystem, int64(info.Size)+1))
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if written != int64(info.Size) {
+		return logger.MarkLocation(location, fmt.Errorf("%w: backup size is %d, expected %d", errs.List().Argument, written, info.Size))
+	}
+
+	if !bytes.Equal(hasher.Sum(nil), backupHash) {
+		return logger.MarkLocation(location, fmt.Errorf("%w: backup hash mismatch", errs.List().Argument))
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	file.Close()
+
+	backupMutex.Lock()
+	defer backupMutex.Unlock()
+
+	prevInfo, err := BackupInfo(network, spAddress)
+	if err != nil && !errors.Is(err, errs.List().StorageSystem) {
+		return logger.MarkLocation(location, err)
+	}
+
+	if err == nil && info.Version <= prevInfo.Version {
+		return logger.MarkLocation(location, errs.List().FsOutdated)
+	}
+
+	err = os.Rename(file.Name(), backupPath)
 	if err != nil {
 		return logger.MarkLocation(location, err)
 	}
@@ -127,3 +755,69 @@ func BackUpSPFsys(spAddress string, fileSystemHeader *multipart.FileHeader) erro
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// BackupInfo returns info of the stored storage provider's filesystem backup.
+func BackupInfo(network, spAddress string) (nodeTypes.FsBackupInfo, error) {
+	const location = "fsys_info.BackupInfo->"
+
+	file, info, err := OpenBackup(network, spAddress)
+	if err != nil {
+		return info, logger.MarkLocation(location, err)
+	}
+
+	file.Close()
+
+	return info, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// OpenBackup opens stored storage provider's filesystem backup and reads its info.
+// Returned file is positioned at the start of backup data, which always matches returned info.
+func OpenBackup(network, spAddress string) (*os.File, nodeTypes.FsBackupInfo, error) {
+	const location = "fsys_info.OpenBackup->"
+
+	var info nodeTypes.FsBackupInfo
+
+	path, found := spFiles.SearchStorageFilesystem(network, spAddress)
+	if !found {
+		return nil, info, logger.MarkLocation(location, errs.List().StorageSystem)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, info, logger.MarkLocation(location, err)
+	}
+
+	header := make([]byte, backupHeaderSize)
+
+	_, err = io.ReadFull(file, header)
+	if err != nil {
+		file.Close()
+		return nil, info, logger.MarkLocation(location, err)
+	}
+
+	infoSize := binary.BigEndian.Uint32(header)
+	if infoSize > maxBackupInfoSize {
+		file.Close()
+		return nil, info, logger.MarkLocation(location, fmt.Errorf("backup info size %d is too big", infoSize))
+	}
+
+	infoJSON := make([]byte, infoSize)
+
+	_, err = io.ReadFull(file, infoJSON)
+	if err != nil {
+		file.Close()
+		return nil, info, logger.MarkLocation(location, err)
+	}
+
+	err = json.Unmarshal(infoJSON, &info)
+	if err != nil {
+		file.Close()
+		return nil, info, logger.MarkLocation(location, err)
+	}
+
+	return file, info, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -1 +1,295 @@
 package fsysinfo_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/errs"
+	fsysInfo "github.com/DeNetPRO/src/fsys_info"
+	"github.com/DeNetPRO/src/hash"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/pb"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/stretchr/testify/require"
+)
+
+const network = "kovan"
+
+func TestMain(m *testing.M) {
+	tstpkg.TestModeOn()
+	defer tstpkg.TestModeOff()
+
+	err := paths.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = config.Create(tstpkg.Data().AccAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	exitVal := m.Run()
+
+	err = os.RemoveAll(paths.List().WorkDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitVal)
+}
+
+func makeFs(t *testing.T, spAddress string, partsCount int) []string {
+	err := os.MkdirAll(filepath.Join(paths.List().Storages[0], network, spAddress), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := make([]string, 0, partsCount)
+
+	for i := 0; i < partsCount; i++ {
+		hSum := sha256.Sum256([]byte(fmt.Sprint(spAddress, i)))
+		parts = append(parts, hex.EncodeToString(hSum[:]))
+	}
+
+	return parts
+}
+
+func TestSaveAndGet(t *testing.T) {
+	const spAddress = "0x0000000000000000000000000000000000000001"
+
+	parts := makeFs(t, spAddress, 7)
+
+	_, tree, err := hash.CalcRoot(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsInfo := &pb.FsInfo{Network: network, SpAddress: spAddress, Nonce: 2, Storage: 7, Signature: "signature"}
+
+	err = fsysInfo.Save(fsInfo, tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spFs, err := fsysInfo.Get(network, spAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, uint32(2), spFs.Nonce)
+	require.Equal(t, uint32(7), spFs.Storage)
+	require.Equal(t, "signature", spFs.SignedFsInfo)
+	require.Equal(t, tree, spFs.Tree)
+
+	leaves, err := fsysInfo.Leaves(network, spAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, part := range parts {
+		require.True(t, leaves[part])
+	}
+
+	fsInfo.Nonce = 1
+
+	err = fsysInfo.Save(fsInfo, tree)
+	require.Error(t, err)
+
+	err = fsysInfo.Remove(network, spAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fsysInfo.Get(network, spAddress)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFsHistory(t *testing.T) {
+	const spAddress = "0x0000000000000000000000000000000000000003"
+
+	parts := makeFs(t, spAddress, 6)
+
+	oldRoot, oldTree, err := hash.CalcRoot(parts[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, newTree, err := hash.CalcRoot(parts[3:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fsysInfo.Save(&pb.FsInfo{Network: network, SpAddress: spAddress, Nonce: 1, Storage: 3}, oldTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fsysInfo.Save(&pb.FsInfo{Network: network, SpAddress: spAddress, Nonce: 2, Storage: 3}, newTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := fsysInfo.History(network, spAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(t, history, 2)
+	require.Equal(t, uint32(2), history[0].Nonce)
+	require.Equal(t, uint32(1), history[1].Nonce)
+
+	oldRootBytes, err := hex.DecodeString(oldRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spFs, err := fsysInfo.Snapshot(network, spAddress, oldRootBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, oldTree, spFs.Tree)
+
+	leaves, err := fsysInfo.Leaves(network, spAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, part := range parts {
+		require.True(t, leaves[part])
+	}
+
+	err = fsysInfo.Prune(network, spAddress, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err = fsysInfo.History(network, spAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(t, history, 1)
+	require.Equal(t, uint32(2), history[0].Nonce)
+}
+
+func TestSaveRejectsUndecodableFs(t *testing.T) {
+	const spAddress = "0x0000000000000000000000000000000000000005"
+
+	parts := makeFs(t, spAddress, 3)
+
+	_, tree, err := hash.CalcRoot(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fsysInfo.Save(&pb.FsInfo{Network: network, SpAddress: spAddress, Nonce: 1, Storage: 3}, tree)
+	require.NoError(t, err)
+
+	_, badTree, err := hash.CalcRoot(append(parts, "abcd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fsysInfo.Save(&pb.FsInfo{Network: network, SpAddress: spAddress, Nonce: 2, Storage: 4}, badTree)
+	require.Error(t, err)
+
+	err = fsysInfo.Save(&pb.FsInfo{Network: network, SpAddress: spAddress, Nonce: 2, Storage: 3, Signature: string(make([]byte, 65536))}, tree)
+	require.Error(t, err)
+
+	leaves, err := fsysInfo.Leaves(network, spAddress)
+	require.NoError(t, err)
+
+	for _, part := range parts {
+		require.True(t, leaves[part])
+	}
+}
+
+func TestLegacyFsMigration(t *testing.T) {
+	const spAddress = "0x0000000000000000000000000000000000000002"
+
+	parts := makeFs(t, spAddress, 4)
+
+	_, tree, err := hash.CalcRoot(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacyFs, err := json.Marshal(nodeTypes.StorageProviderData{Nonce: 3, Storage: 4, SignedFsInfo: "signature", Tree: tree})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pathToSpFiles := filepath.Join(paths.List().Storages[0], network, spAddress)
+
+	err = os.WriteFile(filepath.Join(pathToSpFiles, "sp_fs.json"), legacyFs, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spFs, err := fsysInfo.Get(network, spAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, uint32(3), spFs.Nonce)
+	require.Equal(t, tree, spFs.Tree)
+
+	require.NoFileExists(t, filepath.Join(pathToSpFiles, "sp_fs.json"))
+	require.FileExists(t, filepath.Join(pathToSpFiles, paths.List().SpFsFilename))
+}
+
+func TestFsBackup(t *testing.T) {
+	const spAddress = "0x0000000000000000000000000000000000000004"
+
+	backup := []byte("encrypted file system")
+	backupHash := sha256.Sum256(backup)
+
+	info := nodeTypes.FsBackupInfo{Network: network, Version: 1, Size: uint32(len(backup)), Hash: strings.ToUpper(hex.EncodeToString(backupHash[:]))}
+
+	err := fsysInfo.BackUpSPFsys(network, spAddress, info, bytes.NewReader(backup))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fsysInfo.BackUpSPFsys(network, spAddress, info, bytes.NewReader(backup))
+	require.ErrorIs(t, err, errs.List().FsOutdated)
+
+	info.Version = 2
+
+	err = fsysInfo.BackUpSPFsys(network, spAddress, info, bytes.NewReader(backup[1:]))
+	require.ErrorIs(t, err, errs.List().Argument)
+
+	file, storedInfo, err := fsysInfo.OpenBackup(network, spAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	storedBackup, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, uint32(1), storedInfo.Version)
+	require.Equal(t, backup, storedBackup)
+
+	_, _, err = fsysInfo.OpenBackup(network, "0x0000000000000000000000000000000000000005")
+	require.ErrorIs(t, err, errs.List().StorageSystem)
+
+	_, _, err = fsysInfo.OpenBackup("mumbai", spAddress)
+	require.ErrorIs(t, err, errs.List().StorageSystem)
+}
@@ -0,0 +1,83 @@
+package fsysinfo
+
+import (
+	"container/list"
+
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+)
+
+const maxCachedLeaves = 1 << 19 // about 256 GB of stored parts with built trees
+
+type cacheEntry struct {
+	path    string
+	history []nodeTypes.StorageProviderData
+	leaves  int
+}
+
+// treeCache keeps recently used storage provider fs histories in memory.
+// Size is limited by the total count of cached tree nodes instead of the count of trees,
+// because a single storage provider may have much more parts than others.
+// All methods must be called with fsysinfo mutex locked.
+type treeCache struct {
+	entries map[string]*list.Element
+	order   *list.List
+	leaves  int
+}
+
+var cache = treeCache{
+	entries: map[string]*list.Element{},
+	order:   list.New(),
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c *treeCache) get(path string) ([]nodeTypes.StorageProviderData, bool) {
+	elem, ok := c.entries[path]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*cacheEntry).history, true
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c *treeCache) put(path string, history []nodeTypes.StorageProviderData) {
+	c.remove(path)
+
+	leaves := 0
+
+	for _, spFs := range history {
+		for _, level := range spFs.Tree {
+			leaves += len(level)
+		}
+	}
+
+	if leaves > maxCachedLeaves {
+		return
+	}
+
+	for c.leaves+leaves > maxCachedLeaves {
+		c.remove(c.order.Back().Value.(*cacheEntry).path)
+	}
+
+	c.entries[path] = c.order.PushFront(&cacheEntry{path: path, history: history, leaves: leaves})
+	c.leaves += leaves
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c *treeCache) remove(path string) {
+	elem, ok := c.entries[path]
+	if !ok {
+		return
+	}
+
+	c.leaves -= elem.Value.(*cacheEntry).leaves
+	c.order.Remove(elem)
+	delete(c.entries, path)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	blckChain "github.com/DeNetPRO/src/blockchain_provider"
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/pb"
+	"github.com/DeNetPRO/src/sign"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// on-chain lookups are cached, so every gateway request doesn't make a call to rpc endpoint.
+const lookupLifetime = time.Minute * 10
+
+var mutex sync.Mutex
+
+type lookup struct {
+	registered bool
+	checkedAt  time.Time
+}
+
+var lookups = map[common.Address]lookup{}
+
+var isRegisteredNode = blckChain.IsRegisteredNode
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Authorize checks if gateway is allowed in config or, if on-chain check is enabled, owns a node registered in the network.
+func Authorize(conf nodeTypes.GatewayConfig, gatewayAddress string) error {
+	const location = "gateway.Authorize->"
+
+	for _, allowed := range conf.Allowed {
+		if strings.EqualFold(allowed, gatewayAddress) {
+			return nil
+		}
+	}
+
+	if !conf.CheckOnChain {
+		return logger.MarkLocation(location, errs.List().Gateway)
+	}
+
+	address := common.HexToAddress(gatewayAddress)
+
+	mutex.Lock()
+	cached, found := lookups[address]
+	mutex.Unlock()
+
+	if !found || time.Since(cached.checkedAt) > lookupLifetime {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+		defer cancel()
+
+		registered, err := isRegisteredNode(ctx, address)
+		if err != nil {
+			return logger.MarkLocation(location, err)
+		}
+
+		cached = lookup{registered: registered, checkedAt: time.Now()}
+
+		mutex.Lock()
+		lookups[address] = cached
+		mutex.Unlock()
+	}
+
+	if !cached.registered {
+		return logger.MarkLocation(location, errs.List().Gateway)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// CheckToken verifies that storage provider allowed the gateway to download requested parts.
+func CheckToken(token *pb.DelegationToken, gatewayAddress, network, spAddress string, fileNames []string) error {
+	const location = "gateway.CheckToken->"
+
+	if token == nil {
+		return logger.MarkLocation(location, fmt.Errorf("no delegation token: %w", errs.List().Gateway))
+	}
+
+	if common.HexToAddress(token.SpAddress) != common.HexToAddress(spAddress) ||
+		common.HexToAddress(token.GatewayAddress) != common.HexToAddress(gatewayAddress) ||
+		token.Network != network {
+		return logger.MarkLocation(location, fmt.Errorf("token is issued for another request: %w", errs.List().Gateway))
+	}
+
+	if time.Now().Unix() >= token.ExpiresAt {
+		return logger.MarkLocation(location, fmt.Errorf("token is expired: %w", errs.List().Gateway))
+	}
+
+	if len(token.FileNames) != 0 {
+		allowed := make(map[string]bool, len(token.FileNames))
+
+		for _, fileName := range token.FileNames {
+			allowed[fileName] = true
+		}
+
+		for _, fileName := range fileNames {
+			if !allowed[fileName] {
+				return logger.MarkLocation(location, fmt.Errorf("%s isn't allowed by token: %w", fileName, errs.List().Gateway))
+			}
+		}
+	}
+
+	err := sign.Check(common.HexToAddress(token.SpAddress).String(), token.Signature, TokenHash(token))
+	if err != nil {
+		return logger.MarkLocation(location, fmt.Errorf("%v: %w", err, errs.List().Gateway))
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// TokenHash returns hash of token fields that storage provider signs.
+func TokenHash(token *pb.DelegationToken) [32]byte {
+	expiresAtBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(expiresAtBytes, uint64(token.ExpiresAt))
+
+	data := []byte(token.SpAddress + token.GatewayAddress + token.Network + strings.Join(token.FileNames, ""))
+	data = append(data, expiresAtBytes...)
+
+	return sha256.Sum256(data)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
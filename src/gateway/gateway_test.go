@@ -0,0 +1,77 @@
+package gateway_test
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/encryption"
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/gateway"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/pb"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	gatewayAddress = "0x0000000000000000000000000000000000000001"
+	fileName       = "5f0ab1cb5d8bd1b4ea7ef4ad7ff8fe76c3e1ae4b3db9dbb0e4b2e1bd12d8ad54"
+)
+
+func TestAuthorize(t *testing.T) {
+	conf := nodeTypes.GatewayConfig{Allowed: []string{gatewayAddress}}
+
+	err := gateway.Authorize(conf, gatewayAddress)
+	require.NoError(t, err)
+
+	err = gateway.Authorize(conf, "0x0000000000000000000000000000000000000002")
+	require.ErrorIs(t, err, errs.List().Gateway)
+}
+
+func TestCheckToken(t *testing.T) {
+	privateKeyBytes, err := encryption.DecryptAES(tstpkg.Data().EncrKey, tstpkg.Data().PKHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey, err := crypto.ToECDSA(privateKeyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := &pb.DelegationToken{
+		SpAddress:      tstpkg.Data().AccAddr,
+		GatewayAddress: gatewayAddress,
+		Network:        "kovan",
+		FileNames:      []string{fileName},
+		ExpiresAt:      time.Now().Add(time.Hour).Unix(),
+	}
+
+	tokenHash := gateway.TokenHash(token)
+
+	signature, err := crypto.Sign(tokenHash[:], privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token.Signature = hex.EncodeToString(signature)
+
+	err = gateway.CheckToken(token, gatewayAddress, "kovan", tstpkg.Data().AccAddr, []string{fileName})
+	require.NoError(t, err)
+
+	err = gateway.CheckToken(token, gatewayAddress, "kovan", tstpkg.Data().AccAddr, []string{fileName, "other"})
+	require.ErrorIs(t, err, errs.List().Gateway)
+
+	err = gateway.CheckToken(token, "0x0000000000000000000000000000000000000002", "kovan", tstpkg.Data().AccAddr, []string{fileName})
+	require.ErrorIs(t, err, errs.List().Gateway)
+
+	token.ExpiresAt = time.Now().Unix() - 1
+
+	err = gateway.CheckToken(token, gatewayAddress, "kovan", tstpkg.Data().AccAddr, []string{fileName})
+	require.ErrorIs(t, err, errs.List().Gateway)
+
+	err = gateway.CheckToken(nil, gatewayAddress, "kovan", tstpkg.Data().AccAddr, []string{fileName})
+	require.ErrorIs(t, err, errs.List().Gateway)
+}
@@ -81,11 +81,6 @@ func CalcRoot(hashArr []string) (string, [][][]byte, error) {
 
 	base := make([][]byte, 0, arrLen+1)
 
-	emptyValue, err := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000000")
-	if err != nil {
-		return "", nil, logger.MarkLocation(location, err)
-	}
-
 	for _, v := range hashArr {
 		decoded, err := hex.DecodeString(v)
 		if err != nil {
@@ -94,6 +89,27 @@ func CalcRoot(hashArr []string) (string, [][][]byte, error) {
 		base = append(base, decoded)
 	}
 
+	resByte, err := BuildTree(base)
+	if err != nil {
+		return "", nil, logger.MarkLocation(location, err)
+	}
+
+	return hex.EncodeToString(resByte[len(resByte)-1][0]), resByte, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// BuildTree builds merkle tree levels on top of already decoded base hashes.
+// Base slice is padded with empty value if its length is odd.
+func BuildTree(base [][]byte) ([][][]byte, error) {
+	const location = "hash.BuildTree->"
+
+	if len(base) == 0 {
+		return nil, logger.MarkLocation(location, errors.New("hash array is empty"))
+	}
+
+	emptyValue := make([]byte, sha256.Size)
+
 	if len(base)%2 != 0 {
 		base = append(base, emptyValue)
 	}
@@ -111,7 +127,9 @@ func CalcRoot(hashArr []string) (string, [][][]byte, error) {
 			a := prevList[i*2]
 			b := prevList[i*2+1]
 
-			concatBytes := append(a, b...)
+			concatBytes := make([]byte, 0, len(a)+len(b))
+			concatBytes = append(concatBytes, a...)
+			concatBytes = append(concatBytes, b...)
 			hSum := sha256.Sum256(concatBytes)
 
 			resByte[len(resByte)-1] = append(resByte[len(resByte)-1], hSum[:])
@@ -122,7 +140,7 @@ func CalcRoot(hashArr []string) (string, [][][]byte, error) {
 		}
 	}
 
-	return hex.EncodeToString(resByte[len(resByte)-1][0]), resByte, nil
+	return resByte, nil
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
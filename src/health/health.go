@@ -0,0 +1,319 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/networks"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/upnp"
+	"github.com/ricochet2200/go-disk-usage/du"
+)
+
+// Worker names.
+const (
+	RPCServer = "rpcserver"
+	Proofs    = "proofs"
+	Cleaner   = "cleaner"
+	Admin     = "admin"
+	Config    = "config"
+	IPWatcher = "ipwatcher"
+	NAT       = "nat"
+	Probe     = "probe"
+)
+
+type WorkerStatus struct {
+	Alive    bool   `json:"alive"`
+	LastBeat int64  `json:"lastBeat,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ProofStatus Status is sent, mined or reverted.
+type ProofStatus struct {
+	Time   int64  `json:"time"`
+	Tx     string `json:"tx"`
+	Status string `json:"status"`
+}
+
+type EndpointStatus struct {
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	CheckedAt int64  `json:"checkedAt,omitempty"`
+}
+
+// Status is served on /status, sizes are set in bytes and times in unix seconds.
+type Status struct {
+	Account         string                  `json:"account"`
+	Network         string                  `json:"network"`
+	Registered      bool                    `json:"registered"`
+	Alive           bool                    `json:"alive"`
+	Ready           bool                    `json:"ready"`
+	LastProof       *ProofStatus            `json:"lastProof"`
+	LastBlock       uint64                  `json:"lastBlock"`
+	RPCEndpoint     EndpointStatus          `json:"rpcEndpoint"`
+	StorageLimit    int64                   `json:"storageLimit"`
+	StorageReserved int64                   `json:"storageReserved"`
+	FreeSpace       uint64                  `json:"freeSpace"`
+	Goroutines      int                     `json:"goroutines"`
+	Workers         map[string]WorkerStatus `json:"workers"`
+	PortMapping     upnp.Status             `json:"portMapping"`
+}
+
+// worker must beat at least once in timeout, zero timeout means worker is alive until it's stopped.
+type worker struct {
+	timeout  time.Duration
+	lastBeat time.Time
+	stopped  bool
+	err      error
+}
+
+var (
+	mutex     sync.Mutex
+	workers   = map[string]*worker{}
+	lastProof *ProofStatus
+	lastBlock uint64
+	endpoint  EndpointStatus
+)
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Register starts tracking worker's liveness, it's also used when worker is restarted.
+func Register(name string, timeout time.Duration) {
+	mutex.Lock()
+	workers[name] = &worker{timeout: timeout, lastBeat: time.Now()}
+	mutex.Unlock()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func Beat(name string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	w, ok := workers[name]
+	if ok {
+		w.lastBeat = time.Now()
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Stop marks worker as not alive, err is the reason it stopped if any.
+func Stop(name string, err error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	w, ok := workers[name]
+	if ok {
+		w.stopped = true
+		w.err = err
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func SetLastProof(tx, status string) {
+	mutex.Lock()
+	lastProof = &ProofStatus{Time: time.Now().Unix(), Tx: tx, Status: status}
+	mutex.Unlock()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// SetLastBlock keeps block number received from rpc endpoint, so endpoint is considered reachable.
+func SetLastBlock(blockNum uint64) {
+	mutex.Lock()
+	lastBlock = blockNum
+	endpoint = EndpointStatus{OK: true, CheckedAt: time.Now().Unix()}
+	mutex.Unlock()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// SetEndpointError marks rpc endpoint as unreachable.
+func SetEndpointError(err error) {
+	mutex.Lock()
+	endpoint = EndpointStatus{OK: false, Error: err.Error(), CheckedAt: time.Now().Unix()}
+	mutex.Unlock()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Alive reports if all workers are alive and returns names of the ones that are not.
+func Alive() (bool, []string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return alive(time.Now())
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Ready reports if node is alive, serves rpc requests and can reach rpc endpoint.
+// Endpoint is checked by proofs worker, so it's required only when that worker runs.
+func Ready() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return ready(time.Now())
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// alive must be called with mutex locked.
+func alive(now time.Time) (bool, []string) {
+	dead := []string{}
+
+	for name, w := range workers {
+		if !w.alive(now) {
+			dead = append(dead, name)
+		}
+	}
+
+	sort.Strings(dead)
+
+	return len(dead) == 0, dead
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// ready must be called with mutex locked.
+func ready(now time.Time) bool {
+	isAlive, _ := alive(now)
+
+	rpcServer, ok := workers[RPCServer]
+
+	_, proofsRun := workers[Proofs]
+
+	return isAlive && ok && rpcServer.alive(now) && (endpoint.OK || !proofsRun)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (w *worker) alive(now time.Time) bool {
+	return !w.stopped && (w.timeout == 0 || now.Sub(w.lastBeat) <= w.timeout)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Current returns node status, account and storage info is read from config.
+func Current() (Status, error) {
+	const location = "health.Current->"
+
+	nodeConfig, err := config.Read()
+	if err != nil {
+		return Status{}, logger.MarkLocation(location, err)
+	}
+
+	status := Status{
+		Account:         nodeConfig.Address,
+		Network:         networks.Current(),
+		Registered:      nodeConfig.RegisteredInNetworks[networks.Current()],
+		StorageLimit:    int64(nodeConfig.StorageLimit) * 1024 * 1024 * 1024,
+		StorageReserved: nodeConfig.UsedStorageSpace,
+		Goroutines:      runtime.NumGoroutine(),
+		Workers:         map[string]WorkerStatus{},
+		PortMapping:     upnp.Current(),
+	}
+
+	if len(paths.List().Storages) > 0 {
+		_, err = os.Stat(paths.List().Storages[0])
+		if err == nil {
+			status.FreeSpace = du.NewDiskUsage(paths.List().Storages[0]).Available()
+		}
+	}
+
+	now := time.Now()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	status.Alive, _ = alive(now)
+	status.Ready = ready(now)
+	status.LastBlock = lastBlock
+	status.RPCEndpoint = endpoint
+
+	if lastProof != nil {
+		proof := *lastProof
+		status.LastProof = &proof
+	}
+
+	for name, w := range workers {
+		workerStatus := WorkerStatus{Alive: w.alive(now)}
+
+		if w.timeout > 0 {
+			workerStatus.LastBeat = w.lastBeat.Unix()
+		}
+
+		if w.err != nil {
+			workerStatus.Error = w.err.Error()
+		}
+
+		status.Workers[name] = workerStatus
+	}
+
+	return status, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// RegisterHandlers adds /healthz, /readyz and /status endpoints to mux.
+func RegisterHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", serveHealthz)
+	mux.HandleFunc("/readyz", serveReadyz)
+	mux.HandleFunc("/status", serveStatus)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func serveHealthz(w http.ResponseWriter, r *http.Request) {
+	isAlive, dead := Alive()
+	if !isAlive {
+		http.Error(w, "not alive: "+strings.Join(dead, ", "), http.StatusServiceUnavailable)
+		return
+	}
+
+	fmt.Fprintln(w, "ok")
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func serveReadyz(w http.ResponseWriter, r *http.Request) {
+	if !Ready() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	fmt.Fprintln(w, "ok")
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func serveStatus(w http.ResponseWriter, r *http.Request) {
+	const location = "health.serveStatus->"
+
+	status, err := Current()
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+		http.Error(w, "status is not available", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(status)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
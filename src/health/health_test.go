@@ -0,0 +1,109 @@
+package health_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/health"
+	"github.com/DeNetPRO/src/paths"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	tstpkg.TestModeOn()
+	defer tstpkg.TestModeOff()
+
+	err := paths.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = config.Create(tstpkg.Data().AccAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	exitVal := m.Run()
+
+	err = os.RemoveAll(paths.List().WorkDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitVal)
+}
+
+func get(t *testing.T, mux *http.ServeMux, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+	return rec
+}
+
+// must run before other tests register proofs worker
+func TestReadyWithoutProofs(t *testing.T) {
+	mux := http.NewServeMux()
+	health.RegisterHandlers(mux)
+
+	health.Register(health.RPCServer, 0)
+
+	require.Equal(t, http.StatusOK, get(t, mux, "/readyz").Code)
+}
+
+func TestHealth(t *testing.T) {
+	mux := http.NewServeMux()
+	health.RegisterHandlers(mux)
+
+	health.Register(health.RPCServer, 0)
+	health.Register(health.Proofs, 50*time.Millisecond)
+
+	require.Equal(t, http.StatusOK, get(t, mux, "/healthz").Code)
+	require.Equal(t, http.StatusServiceUnavailable, get(t, mux, "/readyz").Code)
+
+	health.SetLastBlock(100)
+
+	require.Equal(t, http.StatusOK, get(t, mux, "/readyz").Code)
+
+	time.Sleep(100 * time.Millisecond)
+
+	rec := get(t, mux, "/healthz")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), health.Proofs)
+
+	health.Beat(health.Proofs)
+
+	require.Equal(t, http.StatusOK, get(t, mux, "/healthz").Code)
+
+	health.SetLastProof("0x01", "sent")
+	health.SetEndpointError(errors.New("connection refused"))
+
+	require.Equal(t, http.StatusServiceUnavailable, get(t, mux, "/readyz").Code)
+
+	health.Stop(health.RPCServer, errors.New("listener closed"))
+
+	rec = get(t, mux, "/status")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var status health.Status
+
+	err := json.Unmarshal(rec.Body.Bytes(), &status)
+	require.NoError(t, err)
+
+	require.Equal(t, tstpkg.Data().AccAddr, status.Account)
+	require.False(t, status.Alive)
+	require.False(t, status.Ready)
+	require.Equal(t, uint64(100), status.LastBlock)
+	require.Equal(t, "0x01", status.LastProof.Tx)
+	require.False(t, status.RPCEndpoint.OK)
+	require.Equal(t, "connection refused", status.RPCEndpoint.Error)
+	require.Equal(t, health.WorkerStatus{Alive: false, Error: "listener closed"}, status.Workers[health.RPCServer])
+	require.True(t, status.Workers[health.Proofs].Alive)
+}
@@ -0,0 +1,214 @@
+package ipwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	blckChain "github.com/DeNetPRO/src/blockchain_provider"
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/health"
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/reload"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const checkTimeout = 2 * time.Minute
+
+var ErrNotDetected = errors.New("public ip address is not detected")
+
+// Chain reads and updates node info in smart contract.
+type Chain interface {
+	NodeInfo(ctx context.Context) (string, string, error)
+	UpdateNodeInfo(ctx context.Context, ip, port string) error
+}
+
+// Watcher keeps node info in smart contract in sync with detected public ip.
+// Node info is not updated more often than once in MinUpdateInterval, so gas is not wasted
+// while update transaction is pending or ip is flapping.
+type Watcher struct {
+	Sources           []Source
+	Chain             Chain
+	MinUpdateInterval time.Duration
+
+	lastUpdate time.Time
+}
+
+// chain updates node info of the logged in account.
+type chain struct {
+	nodeAddr common.Address
+	password string
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c chain) NodeInfo(ctx context.Context) (string, string, error) {
+	return blckChain.NodeInfo(ctx, c.nodeAddr)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c chain) UpdateNodeInfo(ctx context.Context, ip, port string) error {
+	return blckChain.UpdateNodeInfo(ctx, c.nodeAddr, c.password, ip, port)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Detect returns public ip from the first source that detects it.
+func (w *Watcher) Detect(ctx context.Context) (string, error) {
+	const location = "ipwatcher.Watcher.Detect->"
+
+	for _, source := range w.Sources {
+		ip, err := source.PublicIP(ctx)
+		if err != nil {
+			logger.Debug("public ip is not detected", logger.Fields{"source": source.Name(), "error": err.Error()})
+			continue
+		}
+
+		if !IsPublic(ip) {
+			logger.Debug("detected ip is not public", logger.Fields{"source": source.Name(), "ip": ip.String()})
+			continue
+		}
+
+		return ip.To4().String(), nil
+	}
+
+	return "", logger.MarkLocation(location, ErrNotDetected)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Check compares ip and port in smart contract with detected ip and node port and updates them if they differ.
+// Detected ip is returned, updated is false if node info matches or update is postponed by rate limit.
+func (w *Watcher) Check(ctx context.Context, port string) (ip string, updated bool, err error) {
+	const location = "ipwatcher.Watcher.Check->"
+
+	ip, err = w.Detect(ctx)
+	if err != nil {
+		return "", false, logger.MarkLocation(location, err)
+	}
+
+	port = strings.TrimPrefix(port, ":")
+
+	chainIP, chainPort, err := w.Chain.NodeInfo(ctx)
+	if err != nil {
+		return ip, false, logger.MarkLocation(location, err)
+	}
+
+	if chainIP == ip && chainPort == port {
+		return ip, false, nil
+	}
+
+	if !w.lastUpdate.IsZero() && time.Since(w.lastUpdate) < w.MinUpdateInterval {
+		logger.Warn("node info update is postponed", logger.Fields{"ip": ip, "chainIP": chainIP, "nextUpdate": w.lastUpdate.Add(w.MinUpdateInterval).Unix()})
+		return ip, false, nil
+	}
+
+	fmt.Println("Public address", ip+":"+port, "doesn't match", chainIP+":"+chainPort, "in smart contract, updating node info...")
+
+	w.lastUpdate = time.Now()
+
+	err = w.Chain.UpdateNodeInfo(ctx, ip, port)
+	if err != nil {
+		return ip, false, logger.MarkLocation(location, err)
+	}
+
+	logger.Info("node info is updated", logger.Fields{"ip": ip, "port": port})
+
+	return ip, true, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Start checks public ip until ctx is done. Settings are taken from current config on each check.
+func Start(ctx context.Context, nodeAddr common.Address, password string) error {
+	const location = "ipwatcher.Start->"
+
+	health.Register(health.IPWatcher, 0)
+
+	watcher := &Watcher{Chain: chain{nodeAddr: nodeAddr, password: password}}
+
+	for {
+		nodeConfig := reload.Current()
+		conf := withDefaults(nodeConfig.IPWatcher)
+
+		if !conf.Disabled {
+			watcher.Sources = Sources(conf)
+			watcher.MinUpdateInterval = time.Duration(conf.MinUpdateInterval) * time.Second
+
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+
+			ip, _, err := watcher.Check(checkCtx, nodeConfig.HTTPPort)
+			if err != nil && ctx.Err() == nil {
+				logger.Log(logger.MarkLocation(location, err))
+			}
+
+			cancel()
+
+			if ip != "" && ip != nodeConfig.IpAddress {
+				err = saveIP(ip)
+				if err != nil {
+					logger.Log(logger.MarkLocation(location, err))
+				}
+			}
+		}
+
+		timer := time.NewTimer(time.Duration(conf.CheckInterval) * time.Second)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func withDefaults(conf nodeTypes.IPWatcherConfig) nodeTypes.IPWatcherConfig {
+	if conf.CheckInterval <= 0 {
+		conf.CheckInterval = config.DefaultIPWatcherConfig.CheckInterval
+	}
+
+	if conf.MinUpdateInterval <= 0 {
+		conf.MinUpdateInterval = config.DefaultIPWatcherConfig.MinUpdateInterval
+	}
+
+	return conf
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Sources returns configured echo services first, they see the address node is reached by.
+func Sources(conf nodeTypes.IPWatcherConfig) []Source {
+	list := []Source{}
+
+	for _, url := range conf.EchoServices {
+		list = append(list, EchoSource{URL: url})
+	}
+
+	return append(list, UPnPSource{}, InterfaceSource{Trusted: conf.UseInterfaces})
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// saveIP sets detected ip in config file.
+func saveIP(ip string) error {
+	const location = "ipwatcher.saveIP->"
+
+	err := config.Update(func(nodeConfig *nodeTypes.Config) error {
+		nodeConfig.IpAddress = ip
+		return nil
+	})
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
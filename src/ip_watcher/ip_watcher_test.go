@@ -0,0 +1,114 @@
+package ipwatcher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ipWatcher "github.com/DeNetPRO/src/ip_watcher"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	ip  string
+	err error
+}
+
+func (s fakeSource) Name() string {
+	return "fake"
+}
+
+func (s fakeSource) PublicIP(ctx context.Context) (net.IP, error) {
+	return net.ParseIP(s.ip), s.err
+}
+
+type fakeChain struct {
+	ip      string
+	port    string
+	updates int
+}
+
+func (c *fakeChain) NodeInfo(ctx context.Context) (string, string, error) {
+	return c.ip, c.port, nil
+}
+
+func (c *fakeChain) UpdateNodeInfo(ctx context.Context, ip, port string) error {
+	c.ip, c.port = ip, port
+	c.updates++
+	return nil
+}
+
+func TestDetect(t *testing.T) {
+	watcher := &ipWatcher.Watcher{
+		Sources: []ipWatcher.Source{
+			fakeSource{err: errors.New("not available")},
+			fakeSource{ip: "192.168.1.10"},
+			fakeSource{ip: "100.64.1.1"},
+			fakeSource{ip: "203.0.113.7"},
+		},
+	}
+
+	ip, err := watcher.Detect(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.7", ip)
+
+	watcher.Sources = []ipWatcher.Source{fakeSource{ip: "10.0.0.1"}}
+
+	_, err = watcher.Detect(context.Background())
+	require.ErrorIs(t, err, ipWatcher.ErrNotDetected)
+}
+
+func TestCheck(t *testing.T) {
+	chain := &fakeChain{ip: "203.0.113.7", port: "55050"}
+
+	watcher := &ipWatcher.Watcher{
+		Sources:           []ipWatcher.Source{fakeSource{ip: "203.0.113.7"}},
+		Chain:             chain,
+		MinUpdateInterval: time.Hour,
+	}
+
+	_, updated, err := watcher.Check(context.Background(), ":55050")
+	require.NoError(t, err)
+	require.False(t, updated)
+
+	watcher.Sources = []ipWatcher.Source{fakeSource{ip: "203.0.113.8"}}
+
+	ip, updated, err := watcher.Check(context.Background(), ":55050")
+	require.NoError(t, err)
+	require.True(t, updated)
+	require.Equal(t, "203.0.113.8", ip)
+	require.Equal(t, "203.0.113.8", chain.ip)
+
+	// ip changed again, but update is rate limited
+	watcher.Sources = []ipWatcher.Source{fakeSource{ip: "203.0.113.9"}}
+
+	_, updated, err = watcher.Check(context.Background(), ":55050")
+	require.NoError(t, err)
+	require.False(t, updated)
+	require.Equal(t, 1, chain.updates)
+}
+
+func TestEchoSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "203.0.113.7")
+	}))
+	defer server.Close()
+
+	ip, err := ipWatcher.EchoSource{URL: server.URL}.PublicIP(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.7", ip.String())
+}
+
+func TestSources(t *testing.T) {
+	sources := ipWatcher.Sources(nodeTypes.IPWatcherConfig{EchoServices: []string{"http://echo"}})
+	require.Equal(t, []ipWatcher.Source{ipWatcher.EchoSource{URL: "http://echo"}, ipWatcher.UPnPSource{}, ipWatcher.InterfaceSource{}}, sources)
+
+	sources = ipWatcher.Sources(nodeTypes.IPWatcherConfig{UseInterfaces: true})
+	require.Equal(t, ipWatcher.InterfaceSource{Trusted: true}, sources[len(sources)-1])
+}
@@ -0,0 +1,150 @@
+package ipwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/upnp"
+)
+
+// Source detects public ip address.
+type Source interface {
+	Name() string
+	PublicIP(ctx context.Context) (net.IP, error)
+}
+
+// carrier-grade NAT addresses are not reachable from the internet
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// IsPublic reports if ip is IPv4 address that is reachable from the internet.
+func IsPublic(ip net.IP) bool {
+	ip = ip.To4()
+
+	return ip != nil && ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// EchoSource asks service that responds with caller's ip in plain text.
+type EchoSource struct {
+	URL string
+}
+
+func (s EchoSource) Name() string {
+	return s.URL
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (s EchoSource) PublicIP(ctx context.Context) (net.IP, error) {
+	const location = "ipwatcher.EchoSource.PublicIP->"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, logger.MarkLocation(location, fmt.Errorf("echo service responded with %s", resp.Status))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, logger.MarkLocation(location, errors.New("echo service response is not ip address"))
+	}
+
+	return ip, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// UPnPSource asks UPnP or NAT-PMP gateway for its external address.
+type UPnPSource struct{}
+
+func (UPnPSource) Name() string {
+	return "upnp"
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (UPnPSource) PublicIP(ctx context.Context) (net.IP, error) {
+	const location = "ipwatcher.UPnPSource.PublicIP->"
+
+	ip, err := upnp.ExternalIP(ctx)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	return ip, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// InterfaceSource looks for public address on network interfaces, it works if node is not behind NAT.
+// Address is used only if it's the only global IPv4 address of the host, because other ones may belong
+// to VPN or docker networks. Trusted makes the first public address be used anyway.
+type InterfaceSource struct {
+	Trusted bool
+}
+
+func (InterfaceSource) Name() string {
+	return "interfaces"
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (s InterfaceSource) PublicIP(ctx context.Context) (net.IP, error) {
+	const location = "ipwatcher.InterfaceSource.PublicIP->"
+
+	addresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	var public net.IP
+	globalCount := 0
+
+	for _, address := range addresses {
+		ipNet, ok := address.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+
+		globalCount++
+
+		if public == nil && IsPublic(ipNet.IP) {
+			public = ipNet.IP
+		}
+	}
+
+	if public == nil {
+		return nil, logger.MarkLocation(location, errors.New("no public address on network interfaces"))
+	}
+
+	if globalCount > 1 && !s.Trusted {
+		return nil, logger.MarkLocation(location, fmt.Errorf("%s is not the only address on network interfaces, set useInterfaces to use it", public))
+	}
+
+	return public, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
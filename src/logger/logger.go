@@ -1,99 +1,294 @@
 package logger
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	nodeTypes "github.com/DeNetPRO/src/node_types"
 )
 
-type StatType int
+type Level int
 
 const (
-	Upload StatType = iota
-	Download
-	Delete
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
 )
 
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	logFileName = "node.log"
+)
+
+// Fields add context to log entry, common keys are network, sp, file and tx.
+type Fields map[string]interface{}
+
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  Fields
+	// Err is set for entries made from errors, sinks may use it to get the full error chain.
+	Err error
+}
+
+// Sink receives entries that pass the configured level.
+type Sink interface {
+	Write(entry Entry) error
+}
+
+// locatedError keeps location added by MarkLocation, so it can be logged as a field.
+type locatedError struct {
+	location string
+	line     int
+	err      error
+}
+
+// DefaultConfig logs info messages as text, log file is rotated after 10 MB and 5 rotated files are kept.
+var DefaultConfig = nodeTypes.LoggingConfig{
+	Level:      "info",
+	Format:     FormatText,
+	MaxSize:    10,
+	MaxBackups: 5,
+}
+
 var (
-	SendReports   = true
-	loggerAddress = "http://68.183.215.241:9091"
+	mutex    sync.Mutex
+	minLevel = LevelInfo
+	sinks    = []Sink{&writerSink{w: os.Stdout, format: FormatText}}
+	logFile  *rotatingFile
 )
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-func Log(msg interface{}) {
+// Init sets level and format of entries and starts writing them to rotating log file in dir,
+// unless another dir is set in config. Init may be called again to apply changed config.
+func Init(conf nodeTypes.LoggingConfig, dir string) error {
+	const location = "logger.Init->"
 
-	currentTime := time.Now().Local()
+	level, err := ParseLevel(conf.Level)
+	if err != nil {
+		return MarkLocation(location, err)
+	}
 
-	logMsg := fmt.Sprintf("%s: %v\n", currentTime.String(), msg)
+	format := conf.Format
+	if format == "" {
+		format = DefaultConfig.Format
+	}
 
-	fmt.Println(logMsg)
+	if format != FormatText && format != FormatJSON {
+		return MarkLocation(location, fmt.Errorf("unknown log format %q", conf.Format))
+	}
 
-	if !SendReports {
-		return
+	if conf.MaxSize <= 0 {
+		conf.MaxSize = DefaultConfig.MaxSize
 	}
 
-	errType := fmt.Sprintf("%T", msg)
+	if conf.MaxBackups <= 0 {
+		conf.MaxBackups = DefaultConfig.MaxBackups
+	}
 
-	if errType == "*errors.errorString" || errType == "*fmt.wrapError" {
-		url := loggerAddress + "/logs"
+	if conf.Dir != "" {
+		dir = conf.Dir
+	}
 
-		req, _ := http.NewRequest("POST", url, bytes.NewReader([]byte(logMsg)))
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return MarkLocation(location, err)
+	}
+
+	file, err := openRotatingFile(filepath.Join(dir, logFileName), int64(conf.MaxSize)*1024*1024, conf.MaxBackups)
+	if err != nil {
+		return MarkLocation(location, err)
+	}
 
-		client := &http.Client{Timeout: time.Minute}
+	mutex.Lock()
+	defer mutex.Unlock()
 
-		client.Do(req)
+	if logFile != nil {
+		logFile.Close()
 	}
+
+	logFile = file
+	minLevel = level
+
+	replaced := []Sink{}
+
+	for _, sink := range sinks {
+		if _, ok := sink.(*writerSink); !ok {
+			replaced = append(replaced, sink)
+		}
+	}
+
+	sinks = append([]Sink{
+		&writerSink{w: os.Stdout, format: format},
+		&writerSink{w: file, format: format},
+	}, replaced...)
+
+	return nil
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-// Сreates an informative error with line.
-func MarkLocation(location string, errMsg error) error {
-	_, _, line, _ := runtime.Caller(1)
-	return fmt.Errorf("%s line %d -> %w", location, line, errMsg)
+// AddSink adds sink that receives all entries passing the configured level.
+func AddSink(sink Sink) {
+	mutex.Lock()
+	sinks = append(append([]Sink{}, sinks...), sink)
+	mutex.Unlock()
 }
 
-func SendStatistic(spAddress, network, remoteAddr string, statType StatType, fileSize int64) {
-	const location = "logger.SendStatistic->"
-	url := loggerAddress + "/stats/" + spAddress
-	body := nodeTypes.StatsInfoData{
-		Type:       statType.String(),
-		FileSize:   fileSize,
-		RemoteAddr: remoteAddr,
-		Network:    network,
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func ParseLevel(level string) (Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return LevelDebug, nil
+	case "", "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", level)
 	}
+}
 
-	js, err := json.Marshal(body)
-	if err != nil {
-		Log(MarkLocation(location, err))
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
 	}
+}
 
-	r := bytes.NewReader(js)
-	resp, err := http.Post(url, "application/json", r)
-	if err != nil {
-		Log(MarkLocation(location, err))
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Log logs errors on error level and other messages on info level.
+func Log(msg interface{}) {
+	if err, ok := msg.(error); ok {
+		Error(err, nil)
+		return
 	}
 
-	if resp != nil {
-		resp.Body.Close()
+	Info(fmt.Sprint(msg), nil)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func Debug(msg string, fields Fields) {
+	write(Entry{Level: LevelDebug, Message: msg, Fields: fields})
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func Info(msg string, fields Fields) {
+	write(Entry{Level: LevelInfo, Message: msg, Fields: fields})
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func Warn(msg string, fields Fields) {
+	write(Entry{Level: LevelWarn, Message: msg, Fields: fields})
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Error logs error with locations added by MarkLocation set as location field.
+func Error(err error, fields Fields) {
+	message, locations := splitLocations(err)
+
+	entryFields := Fields{}
+
+	for key, value := range fields {
+		entryFields[key] = value
+	}
+
+	if len(locations) > 0 {
+		entryFields["location"] = strings.Join(locations, " -> ")
 	}
+
+	write(Entry{Level: LevelError, Message: message, Fields: entryFields, Err: err})
 }
 
-func (s StatType) String() string {
-	switch s {
-	case Upload:
-		return "Upload"
-	case Download:
-		return "Download"
-	case Delete:
-		return "Delete"
-	default:
-		return "Upload"
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func write(entry Entry) {
+	entry.Time = time.Now().Local()
+
+	mutex.Lock()
+	level := minLevel
+	current := sinks
+	mutex.Unlock()
+
+	if entry.Level < level {
+		return
+	}
+
+	for _, sink := range current {
+		err := sink.Write(entry)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "logger:", err)
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// splitLocations returns message of the error without locations and locations from outer to inner one.
+func splitLocations(err error) (string, []string) {
+	locations := []string{}
+
+	for err != nil {
+		located, ok := err.(*locatedError)
+		if !ok {
+			break
+		}
+
+		locations = append(locations, fmt.Sprintf("%s line %d", strings.TrimSpace(located.location), located.line))
+		err = located.err
 	}
+
+	if err == nil {
+		return "", locations
+	}
+
+	return err.Error(), locations
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Сreates an informative error with line.
+func MarkLocation(location string, errMsg error) error {
+	_, _, line, _ := runtime.Caller(1)
+	return &locatedError{location: location, line: line, err: errMsg}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (e *locatedError) Error() string {
+	return fmt.Sprintf("%s line %d -> %v", e.location, e.line, e.err)
 }
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (e *locatedError) Unwrap() error {
+	return e.err
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,76 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkLocation(t *testing.T) {
+	errTest := errors.New("test error")
+
+	err := logger.MarkLocation("outer->", logger.MarkLocation("inner->", errTest))
+
+	require.ErrorIs(t, err, errTest)
+	require.Regexp(t, `^outer-> line \d+ -> inner-> line \d+ -> test error$`, err.Error())
+}
+
+func TestJSON(t *testing.T) {
+	dir := t.TempDir()
+
+	err := logger.Init(nodeTypes.LoggingConfig{Level: "warn", Format: logger.FormatJSON}, dir)
+	require.NoError(t, err)
+
+	logger.Info("skipped", nil)
+	logger.Error(logger.MarkLocation("test.Func->", errors.New("test error")), logger.Fields{"network": "kovan", "sp": "0x01"})
+
+	logBytes, err := os.ReadFile(filepath.Join(dir, "node.log"))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(logBytes)), "\n")
+	require.Len(t, lines, 1)
+
+	entry := map[string]interface{}{}
+
+	err = json.Unmarshal([]byte(lines[0]), &entry)
+	require.NoError(t, err)
+
+	require.Equal(t, "error", entry["level"])
+	require.Equal(t, "test error", entry["msg"])
+	require.Equal(t, "kovan", entry["network"])
+	require.Equal(t, "0x01", entry["sp"])
+	require.Regexp(t, `^test.Func-> line \d+$`, entry["location"])
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	err := logger.Init(nodeTypes.LoggingConfig{Level: "debug", Format: logger.FormatText, MaxSize: 1, MaxBackups: 2}, dir)
+	require.NoError(t, err)
+
+	message := strings.Repeat("a", 1024)
+
+	for i := 0; i < 3*1024; i++ {
+		logger.Debug(message, logger.Fields{"file": "part name"})
+	}
+
+	for _, name := range []string{"node.log", "node.log.1", "node.log.2"} {
+		stat, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.LessOrEqual(t, stat.Size(), int64(1024*1024))
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "node.log.3"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	logBytes, err := os.ReadFile(filepath.Join(dir, "node.log"))
+	require.NoError(t, err)
+	require.Contains(t, string(logBytes), ` DEBUG `+message+` file="part name"`+"\n")
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// writerSink writes entries line by line as text or json.
+type writerSink struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	format string
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (s *writerSink) Write(entry Entry) error {
+	var line []byte
+
+	if s.format == FormatJSON {
+		jsonLine, err := formatJSON(entry)
+		if err != nil {
+			return err
+		}
+
+		line = jsonLine
+	} else {
+		line = formatText(entry)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.w.Write(line)
+
+	return err
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// formatText formats entry as "time level message key=value ...", fields are sorted by key.
+func formatText(entry Entry) []byte {
+	var b strings.Builder
+
+	b.WriteString(entry.Time.Format(timeFormat))
+	b.WriteString(" ")
+	b.WriteString(strings.ToUpper(entry.Level.String()))
+	b.WriteString(" ")
+	b.WriteString(entry.Message)
+
+	for _, key := range sortedKeys(entry.Fields) {
+		value := fmt.Sprint(entry.Fields[key])
+
+		if strings.ContainsAny(value, " \"=\n") || value == "" {
+			value = strconv.Quote(value)
+		}
+
+		b.WriteString(" ")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(value)
+	}
+
+	b.WriteString("\n")
+
+	return []byte(b.String())
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// formatJSON formats entry as json object with time, level and msg keys, fields are added as they are.
+func formatJSON(entry Entry) ([]byte, error) {
+	object := make(map[string]interface{}, len(entry.Fields)+3)
+
+	for key, value := range entry.Fields {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+
+		object[key] = value
+	}
+
+	object["time"] = entry.Time.Format(time.RFC3339Nano)
+	object["level"] = entry.Level.String()
+	object["msg"] = entry.Message
+
+	line, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(line, '\n'), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -15,7 +15,5 @@ func main() {
 		log.Fatal("Fatal Error: couldn't locate home directory")
 	}
 
-	// upnp.Init()
-
 	cmd.Execute()
 }
@@ -0,0 +1,57 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DeNetPRO/src/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	requests := metrics.NewCounter("test_requests_total", "Test requests.", "method", "code")
+	temperature := metrics.NewGauge("test_temperature", "Test gauge.")
+	latency := metrics.NewHistogram("test_latency_seconds", "Test histogram.", []float64{0.1, 1}, "network")
+
+	requests.Inc("Upload", "OK")
+	requests.Add(2, "Upload", "OK")
+	requests.Add(-1, "Upload", "OK")
+	requests.Inc(`Down"load`, "Internal")
+
+	metrics.OnScrape(func() {
+		temperature.Set(36.6)
+	})
+
+	latency.Observe(0.05, "kovan")
+	latency.Observe(0.5, "kovan")
+	latency.Observe(5, "kovan")
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	expected := []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{method="Down\"load",code="Internal"} 1`,
+		`test_requests_total{method="Upload",code="OK"} 3`,
+		"# TYPE test_temperature gauge",
+		"test_temperature 36.6",
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{network="kovan",le="0.1"} 1`,
+		`test_latency_seconds_bucket{network="kovan",le="1"} 2`,
+		`test_latency_seconds_bucket{network="kovan",le="+Inf"} 3`,
+		`test_latency_seconds_sum{network="kovan"} 5.55`,
+		`test_latency_seconds_count{network="kovan"} 3`,
+		"# TYPE denode_rpc_errors_total counter",
+	}
+
+	for _, line := range expected {
+		require.Contains(t, string(body), line+"\n")
+	}
+
+	require.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+}
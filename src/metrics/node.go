@@ -0,0 +1,34 @@
+package metrics
+
+// Node metrics, sizes are set in bytes, amounts of ether in wei.
+var (
+	Uploads        = NewCounter("denode_uploads_total", "Completed file uploads.", "network")
+	UploadBytes    = NewCounter("denode_upload_bytes_total", "Bytes received from storage providers.", "network")
+	UploadDuration = NewHistogram("denode_upload_duration_seconds", "Duration of completed file uploads.", DurationBuckets, "network")
+
+	Downloads        = NewCounter("denode_downloads_total", "Completed file downloads.", "network", "gateway")
+	DownloadBytes    = NewCounter("denode_download_bytes_total", "Bytes sent to storage providers and gateways.", "network", "gateway")
+	DownloadDuration = NewHistogram("denode_download_duration_seconds", "Duration of completed file downloads.", DurationBuckets, "network", "gateway")
+
+	StorageLimit    = NewGauge("denode_storage_limit_bytes", "Storage space shared by the node.")
+	StorageReserved = NewGauge("denode_storage_reserved_bytes", "Storage space reserved for uploads.")
+	StorageUsed     = NewGauge("denode_storage_used_bytes", "Storage space used by stored parts.", "network")
+
+	Bandwidth      = NewGauge("denode_bandwidth_bytes_per_second", "Throughput averaged over the last 10 seconds.", "direction")
+	BandwidthLimit = NewGauge("denode_bandwidth_limit_bytes_per_second", "Bandwidth limit applied now, 0 means no limit.", "direction")
+
+	ProofAttempts  = NewCounter("denode_proof_attempts_total", "Proofs sent to smart contract.", "network")
+	ProofSuccesses = NewCounter("denode_proof_successes_total", "Proof transactions accepted by network.", "network")
+	ProofFailures  = NewCounter("denode_proof_failures_total", "Proofs that were not sent.", "network", "reason")
+	GasSpent       = NewCounter("denode_gas_spent_wei_total", "Fees paid for mined proof transactions.", "network")
+	WalletBalance  = NewGauge("denode_wallet_balance_wei", "Node wallet balance at the last check.", "network")
+
+	CleanerDeletedParts = NewCounter("denode_cleaner_deleted_parts_total", "Parts deleted by cleaner.")
+	CleanerDeletedBytes = NewCounter("denode_cleaner_deleted_bytes_total", "Bytes freed by cleaner.")
+	CleanerEvictions    = NewCounter("denode_cleaner_evictions_total", "Storage providers whose parts were removed because of zero balance.", "network")
+	CleanerEvictedParts = NewCounter("denode_cleaner_evicted_parts_total", "Parts removed because storage provider's balance was zero.", "network")
+
+	RPCErrors = NewCounter("denode_rpc_errors_total", "Errors returned by rpc endpoints.", "method", "code")
+
+	TelemetryDropped = NewCounter("denode_telemetry_dropped_events_total", "Telemetry events dropped because queue was full or sending failed.")
+)
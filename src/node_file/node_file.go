@@ -66,6 +66,7 @@ func ReadDirFiles(path string) ([]fs.FileInfo, error) {
 	if err != nil {
 		return nil, logger.MarkLocation(location, err)
 	}
+	defer dir.Close()
 
 	files, err := dir.Readdir(0)
 	if err != nil {
@@ -11,6 +11,179 @@ type Config struct {
 	UsedStorageSpace     int64             `json:"usedStorageSpace"`
 	SendBugReports       bool              `json:"sendBugReports"`
 	RegisteredInNetworks map[string]bool   `json:"registeredInNetworks"`
+	Cleaner              CleanerConfig     `json:"cleaner"`
+	Quotas               QuotaConfig       `json:"quotas"`
+	Gateways             GatewayConfig     `json:"gateways"`
+	TLS                  TLSConfig         `json:"tls"`
+	Limits               LimitsConfig      `json:"limits"`
+	Traffic              TrafficConfig     `json:"traffic"`
+	Bandwidth            BandwidthConfig   `json:"bandwidth"`
+	Metrics              MetricsConfig     `json:"metrics"`
+	Health               HealthConfig      `json:"health"`
+	Logging              LoggingConfig     `json:"logging"`
+	Telemetry            TelemetryConfig   `json:"telemetry"`
+	Admin                AdminConfig       `json:"admin"`
+	IPWatcher            IPWatcherConfig   `json:"ipWatcher"`
+	NAT                  NATConfig         `json:"nat"`
+}
+
+// NATConfig Node port is mapped on UPnP or NAT-PMP gateway for Lifetime seconds and mapping is renewed
+// until node is stopped. IGD is url of UPnP device description and Gateway is NAT-PMP gateway address,
+// they are discovered if not set. Disabled turns port mapping off, e.g. if port is forwarded manually.
+type NATConfig struct {
+	Disabled bool   `json:"disabled"`
+	IGD      string `json:"igd"`
+	Gateway  string `json:"gateway"`
+	Lifetime int64  `json:"lifetime"`
+}
+
+// IPWatcherConfig Public ip is detected every CheckInterval seconds using EchoServices (urls that respond
+// with caller's ip in plain text), UPnP and network interfaces. Address of network interfaces is used only
+// if it's the only global one, UseInterfaces makes the first public one be used anyway. Node info in smart contract
+// is updated if it doesn't match, but not more often than once in MinUpdateInterval seconds. Zero values are replaced with defaults.
+type IPWatcherConfig struct {
+	Disabled          bool     `json:"disabled"`
+	EchoServices      []string `json:"echoServices"`
+	UseInterfaces     bool     `json:"useInterfaces"`
+	CheckInterval     int64    `json:"checkInterval"`
+	MinUpdateInterval int64    `json:"minUpdateInterval"`
+}
+
+// AdminConfig Admin API is served on Address, empty Address means unix socket in work dir.
+// Only loopback addresses are accepted, Disabled turns admin API off.
+type AdminConfig struct {
+	Disabled bool   `json:"disabled"`
+	Address  string `json:"address"`
+}
+
+// TelemetryConfig Statistics and, if SendBugReports is set, error reports are sent to Endpoint in batches
+// and appended to File, if it's set. Empty Endpoint means default collector, it's used unless Disabled is set
+// and it's reached over plain http. Unless SendAddresses is set, addresses are replaced with hashes keyed
+// by secret of the node installation and remote addresses are dropped.
+// FlushInterval is set in seconds, zero values are replaced with defaults.
+type TelemetryConfig struct {
+	Disabled      bool   `json:"disabled"`
+	Endpoint      string `json:"endpoint"`
+	File          string `json:"file"`
+	SendAddresses bool   `json:"sendAddresses"`
+	QueueSize     int    `json:"queueSize"`
+	BatchSize     int    `json:"batchSize"`
+	FlushInterval int64  `json:"flushInterval"`
+}
+
+// LoggingConfig Level is one of debug, info, warn, error and Format is text or json. Log file is rotated
+// when it grows over MaxSize megabytes, MaxBackups rotated files are kept. Empty Dir means logs dir of work dir.
+type LoggingConfig struct {
+	Level      string `json:"level"`
+	Format     string `json:"format"`
+	Dir        string `json:"dir"`
+	MaxSize    int    `json:"maxSize"`
+	MaxBackups int    `json:"maxBackups"`
+}
+
+// MetricsConfig If Enabled is set, metrics are served in Prometheus format on Address at /metrics.
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+}
+
+// HealthConfig If Enabled is set, /healthz, /readyz and /status are served on Address.
+// Metrics and health endpoints share listener if their addresses are the same.
+type HealthConfig struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+}
+
+// BandwidthLimits are set in bytes per second, zero value means no limit.
+// Sp limits are applied to each storage provider, gateway limit is applied to all gateway downloads together.
+type BandwidthLimits struct {
+	Upload          int64 `json:"upload"`
+	Download        int64 `json:"download"`
+	SpUpload        int64 `json:"spUpload"`
+	SpDownload      int64 `json:"spDownload"`
+	GatewayDownload int64 `json:"gatewayDownload"`
+}
+
+// BandwidthSchedule replaces limits from From hour till To hour of local time, To may be less than From
+// for schedules that pass midnight.
+type BandwidthSchedule struct {
+	From   int             `json:"from"`
+	To     int             `json:"to"`
+	Limits BandwidthLimits `json:"limits"`
+}
+
+// BandwidthConfig Limits are applied unless one of schedules is active.
+type BandwidthConfig struct {
+	Limits    BandwidthLimits     `json:"limits"`
+	Schedules []BandwidthSchedule `json:"schedules"`
+}
+
+// TrafficConfig If Enforce is set, parts are served only while storage provider's downloaded traffic
+// is within paid traffic plus FreeBytes.
+type TrafficConfig struct {
+	Enforce   bool   `json:"enforce"`
+	FreeBytes uint64 `json:"freeBytes"`
+}
+
+// LimitsConfig rates are set in requests per second, sizes in bytes and idle timeout in seconds.
+// Zero values are replaced with defaults.
+type LimitsConfig struct {
+	IPRate                float64 `json:"ipRate"`
+	IPBurst               int     `json:"ipBurst"`
+	SpRate                float64 `json:"spRate"`
+	SpBurst               int     `json:"spBurst"`
+	MaxMessageSize        int     `json:"maxMessageSize"`
+	MaxStreamSize         int64   `json:"maxStreamSize"`
+	MaxConcurrentStreams  uint32  `json:"maxConcurrentStreams"`
+	MaxConcurrentRequests int     `json:"maxConcurrentRequests"`
+	IdleTimeout           int64   `json:"idleTimeout"`
+}
+
+// TLSConfig If CertFile and KeyFile are empty, self-signed certificate bound to node's address is generated.
+// If ClientCAFile is set, gateways must present client certificate signed by one of its CAs.
+type TLSConfig struct {
+	Enabled      bool   `json:"enabled"`
+	CertFile     string `json:"certFile"`
+	KeyFile      string `json:"keyFile"`
+	ClientCAFile string `json:"clientCAFile"`
+}
+
+// GatewayConfig Allowed gateways can download parts with storage provider's delegation token.
+// If CheckOnChain is set, gateways that own a node registered in the network are allowed too.
+type GatewayConfig struct {
+	Allowed      []string `json:"allowed"`
+	CheckOnChain bool     `json:"checkOnChain"`
+}
+
+// Quota limits stored data by absolute size in bytes and by percentage of storage limit.
+// Zero value means no limit, if both are set the lower one is used.
+type Quota struct {
+	Bytes   int64   `json:"bytes"`
+	Percent float64 `json:"percent"`
+}
+
+// QuotaConfig Default quota is applied to each storage provider unless there is an override in StorageProviders.
+// Networks quotas limit total data stored in network.
+type QuotaConfig struct {
+	Default          Quota            `json:"default"`
+	StorageProviders map[string]Quota `json:"storageProviders"`
+	Networks         map[string]Quota `json:"networks"`
+}
+
+// CleanerConfig periods are set in seconds.
+type CleanerConfig struct {
+	GracePeriod         int64    `json:"gracePeriod"`
+	QuarantinePeriod    int64    `json:"quarantinePeriod"`
+	EvictionGracePeriod int64    `json:"evictionGracePeriod"`
+	ProtectedSPs        []string `json:"protectedSPs"`
+}
+
+type CleanerReport struct {
+	Marked      []string
+	Quarantined []string
+	Restored    []string
+	Deleted     []string
+	Evicted     []string
 }
 
 type NtwrkParams struct {
@@ -27,13 +200,6 @@ type UpdatedFsInfo struct {
 	SignedFsRootNonceHash string   `json:"signedFsRootNonceHash"`
 }
 
-type StatsInfoData struct {
-	Type       string
-	FileSize   int64
-	RemoteAddr string
-	Network    string
-}
-
 type ReqData struct {
 	RequesterAddr string
 	FileName      string
@@ -44,9 +210,18 @@ type StorageProviderData struct {
 	Nonce        uint32     `json:"nonce"`
 	Storage      uint32     `json:"storage"`
 	SignedFsInfo string     `json:"signedFsRoot"`
+	Root         []byte     `json:"root,omitempty"`
 	Tree         [][][]byte `json:"tree"`
 }
 
+type FsBackupInfo struct {
+	Network   string `json:"network"`
+	Version   uint32 `json:"version"`
+	Size      uint32 `json:"size"`
+	Hash      string `json:"hash"`
+	Signature string `json:"signature"`
+}
+
 type NodesResponse struct {
 	Nodes []string `json:"nodes"`
 }
@@ -69,6 +244,16 @@ type ErrList struct {
 	Internal      error
 	Argument      error
 	StorageSystem error
+	FsOutdated    error
+	FileSize      error
+	Quota         error
+	Nonce         error
+	Gateway       error
+	RateLimit     error
+	StreamSize    error
+	IdleStream    error
+	NotFound      error
+	Traffic       error
 }
 
 type Paths struct {
@@ -79,5 +264,6 @@ type Paths struct {
 	UpdateDir    string
 	SysDir       string
 	SpFsFilename string
+	AdminSocket  string
 	Storages     []string
 }
@@ -3,13 +3,17 @@ package paths
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 
+	"github.com/DeNetPRO/src/errs"
 	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/networks"
 	nodeTypes "github.com/DeNetPRO/src/node_types"
 	tstpkg "github.com/DeNetPRO/src/tst_pkg"
 )
@@ -20,7 +24,12 @@ var (
 	confFileName = "config.json"
 )
 
-var paths = nodeTypes.Paths{SpFsFilename: "sp_fs.json"}
+var paths = nodeTypes.Paths{SpFsFilename: "sp_fs.bin"}
+
+var (
+	regAddr     = regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
+	regPartName = regexp.MustCompile("^[0-9a-fA-F]{64}$")
+)
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
@@ -40,6 +49,7 @@ func Init() error {
 	paths.AccsDir = filepath.Join(paths.WorkDir, "accounts")
 	paths.UpdateDir = filepath.Join(paths.WorkDir, "update")
 	paths.SysDir = filepath.Join(paths.WorkDir, "systems")
+	paths.AdminSocket = filepath.Join(paths.WorkDir, "admin.sock")
 
 	return nil
 }
@@ -147,3 +157,141 @@ func CreateStorage(path string) error {
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// CheckAddress returns error if address doesn't match ethereum address format.
+func CheckAddress(address string) error {
+	const location = "paths.CheckAddress->"
+
+	if !regAddr.MatchString(address) {
+		return logger.MarkLocation(location, fmt.Errorf("%q: %w", address, errs.List().Argument))
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// CheckPartName returns error if part name isn't 64 hex symbols, i.e. hex encoded sha256 of the part.
+func CheckPartName(fileName string) error {
+	const location = "paths.CheckPartName->"
+
+	if !regPartName.MatchString(fileName) {
+		return logger.MarkLocation(location, fmt.Errorf("%q: %w", fileName, errs.List().FileName))
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// SpFilesDir returns path to storage provider's directory in the storage. Client supplied
+// network and address are validated, so the path can't point outside the storage.
+func SpFilesDir(network, spAddress string) (string, error) {
+	const location = "paths.SpFilesDir->"
+
+	err := networks.Check(network)
+	if err != nil {
+		return "", logger.MarkLocation(location, err)
+	}
+
+	err = CheckAddress(spAddress)
+	if err != nil {
+		return "", logger.MarkLocation(location, err)
+	}
+
+	if len(paths.Storages) == 0 {
+		return "", logger.MarkLocation(location, errors.New("path to storage is not set"))
+	}
+
+	pathToSpFiles := filepath.Join(paths.Storages[0], network, spAddress)
+
+	err = checkWithin(paths.Storages[0], pathToSpFiles)
+	if err != nil {
+		return "", logger.MarkLocation(location, err)
+	}
+
+	return pathToSpFiles, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// PartFile returns path to storage provider's file part. Part name must be 64 hex symbols.
+func PartFile(network, spAddress, fileName string) (string, error) {
+	const location = "paths.PartFile->"
+
+	err := CheckPartName(fileName)
+	if err != nil {
+		return "", logger.MarkLocation(location, err)
+	}
+
+	pathToSpFiles, err := SpFilesDir(network, spAddress)
+	if err != nil {
+		return "", logger.MarkLocation(location, err)
+	}
+
+	pathToFile := filepath.Join(pathToSpFiles, fileName)
+
+	err = checkWithin(paths.Storages[0], pathToFile)
+	if err != nil {
+		return "", logger.MarkLocation(location, err)
+	}
+
+	return pathToFile, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// checkWithin returns error if path is outside of the root, symlinks of existing paths are resolved.
+func checkWithin(root, path string) error {
+	const location = "paths.checkWithin->"
+
+	resolvedRoot, err := resolveExisting(root)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	resolvedPath, err := resolveExisting(path)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	rel, err := filepath.Rel(resolvedRoot, resolvedPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return logger.MarkLocation(location, fmt.Errorf("%q is outside of storage: %w", path, errs.List().Argument))
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// resolveExisting resolves symlinks of the longest existing part of the path.
+func resolveExisting(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	missing := ""
+
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(resolved, missing), nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(path, missing), nil
+		}
+
+		missing = filepath.Join(filepath.Base(path), missing)
+		path = parent
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
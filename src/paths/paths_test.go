@@ -0,0 +1,89 @@
+package paths_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeNetPRO/src/paths"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	network   = "kovan"
+	spAddress = "0x5Cae405D9A28B51D1bDfFdF6B22c97D2E78dc527"
+	partName  = "5f0ab1cb5d8bd1b4ea7ef4ad7ff8fe76c3e1ae4b3db9dbb0e4b2e1bd12d8ad54"
+)
+
+func TestPartFile(t *testing.T) {
+	storage := t.TempDir()
+	paths.SetStoragePaths([]string{storage})
+
+	pathToFile, err := paths.PartFile(network, spAddress, partName)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(storage, network, spAddress, partName), pathToFile)
+
+	invalid := [][3]string{
+		{"../kovan", spAddress, partName},
+		{"unknown", spAddress, partName},
+		{network, "../../accounts", partName},
+		{network, "0x5Cae405D9A28B51D1bDfFdF6B22c97D2E78dc52", partName},
+		{network, spAddress, "../" + partName[3:]},
+		{network, spAddress, "../../../accounts/keystore"},
+		{network, spAddress, ""},
+		{network, spAddress, strings.Repeat("g", 64)},
+	}
+
+	for _, args := range invalid {
+		_, err := paths.PartFile(args[0], args[1], args[2])
+		require.Error(t, err, args)
+	}
+}
+
+func TestSymlinkOutsideStorage(t *testing.T) {
+	storage := t.TempDir()
+	outside := t.TempDir()
+	paths.SetStoragePaths([]string{storage})
+
+	err := os.MkdirAll(filepath.Join(storage, network), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Symlink(outside, filepath.Join(storage, network, spAddress))
+	if err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+
+	_, err = paths.PartFile(network, spAddress, partName)
+	require.Error(t, err)
+}
+
+func FuzzPartFile(f *testing.F) {
+	storage := f.TempDir()
+	paths.SetStoragePaths([]string{storage})
+
+	f.Add(network, spAddress, partName)
+	f.Add(network, spAddress, "../../../accounts/keystore")
+	f.Add("..", spAddress, partName)
+	f.Add(network, "..\\..", partName)
+	f.Add(network, spAddress+"/..", partName)
+	f.Add(network, spAddress, partName+"\x00")
+
+	f.Fuzz(func(t *testing.T, network, spAddress, fileName string) {
+		pathToFile, err := paths.PartFile(network, spAddress, fileName)
+		if err != nil {
+			return
+		}
+
+		rel, err := filepath.Rel(storage, pathToFile)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			t.Fatalf("%q is outside of storage", pathToFile)
+		}
+
+		if filepath.Base(pathToFile) != fileName || filepath.Base(filepath.Dir(pathToFile)) != spAddress {
+			t.Fatalf("unexpected path %q for %q %q %q", pathToFile, network, spAddress, fileName)
+		}
+	})
+}
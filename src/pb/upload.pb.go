@@ -20,6 +20,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// FileSystemState is used to describe how stored parts correspond to the updated fs.
+type FileSystemState int32
+
+const (
+	FileSystemState_INVALID FileSystemState = 0
+	// ACTUAL returned when node stores all parts of the updated fs.
+	FileSystemState_ACTUAL FileSystemState = 1
+	// INCOMPLETE returned when some parts of the updated fs are missing on the node.
+	FileSystemState_INCOMPLETE FileSystemState = 2
+	// OLD returned when node already has fs info with greater nonce.
+	FileSystemState_OLD FileSystemState = 3
+)
+
+// Enum value maps for FileSystemState.
+var (
+	FileSystemState_name = map[int32]string{
+		0: "INVALID",
+		1: "ACTUAL",
+		2: "INCOMPLETE",
+		3: "OLD",
+	}
+	FileSystemState_value = map[string]int32{
+		"INVALID":    0,
+		"ACTUAL":     1,
+		"INCOMPLETE": 2,
+		"OLD":        3,
+	}
+)
+
+func (x FileSystemState) Enum() *FileSystemState {
+	p := new(FileSystemState)
+	*p = x
+	return p
+}
+
+func (x FileSystemState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FileSystemState) Descriptor() protoreflect.EnumDescriptor {
+	return file_upload_proto_enumTypes[0].Descriptor()
+}
+
+func (FileSystemState) Type() protoreflect.EnumType {
+	return &file_upload_proto_enumTypes[0]
+}
+
+func (x FileSystemState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FileSystemState.Descriptor instead.
+func (FileSystemState) EnumDescriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{0}
+}
+
 type Response struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -67,24 +123,274 @@ func (x *Response) GetMsg() string {
 	return ""
 }
 
+type NonceRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Signer []byte `protobuf:"bytes,1,opt,name=signer,proto3" json:"signer,omitempty"` // address of the account that is going to sign the request
+}
+
+func (x *NonceRequest) Reset() {
+	*x = NonceRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NonceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NonceRequest) ProtoMessage() {}
+
+func (x *NonceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NonceRequest.ProtoReflect.Descriptor instead.
+func (*NonceRequest) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NonceRequest) GetSigner() []byte {
+	if x != nil {
+		return x.Signer
+	}
+	return nil
+}
+
+// Nonce is valid for a single request of the signer until expires_at (unix timestamp).
+type Nonce struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nonce     []byte `protobuf:"bytes,1,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	ExpiresAt int64  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (x *Nonce) Reset() {
+	*x = Nonce{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Nonce) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Nonce) ProtoMessage() {}
+
+func (x *Nonce) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Nonce.ProtoReflect.Descriptor instead.
+func (*Nonce) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Nonce) GetNonce() []byte {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *Nonce) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// Signature authenticates a single request:
+// signed_bytes = sign(sha256(method + network + payload_hash + nonce + timestamp)),
+// where method is the rpc name, e.g. "UploadFile", timestamp is unix time in seconds (8 bytes, big endian)
+// and payload_hash is the sha256 of the request payload described next to the sign field.
+type Signature struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Signer      []byte `protobuf:"bytes,2,opt,name=signer,proto3" json:"signer,omitempty"`
+	Nonce       []byte `protobuf:"bytes,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	SignedBytes []byte `protobuf:"bytes,4,opt,name=signed_bytes,json=signedBytes,proto3" json:"signed_bytes,omitempty"`
+	Timestamp   int64  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *Signature) Reset() {
+	*x = Signature{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Signature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signature) ProtoMessage() {}
+
+func (x *Signature) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signature.ProtoReflect.Descriptor instead.
+func (*Signature) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Signature) GetSigner() []byte {
+	if x != nil {
+		return x.Signer
+	}
+	return nil
+}
+
+func (x *Signature) GetNonce() []byte {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *Signature) GetSignedBytes() []byte {
+	if x != nil {
+		return x.SignedBytes
+	}
+	return nil
+}
+
+func (x *Signature) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type FileSystemStateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Msg          string          `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	State        FileSystemState `protobuf:"varint,2,opt,name=state,proto3,enum=loads.FileSystemState" json:"state,omitempty"`
+	MissingParts []string        `protobuf:"bytes,3,rep,name=missing_parts,json=missingParts,proto3" json:"missing_parts,omitempty"`
+	ExtraParts   []string        `protobuf:"bytes,4,rep,name=extra_parts,json=extraParts,proto3" json:"extra_parts,omitempty"`
+}
+
+func (x *FileSystemStateResponse) Reset() {
+	*x = FileSystemStateResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FileSystemStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileSystemStateResponse) ProtoMessage() {}
+
+func (x *FileSystemStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileSystemStateResponse.ProtoReflect.Descriptor instead.
+func (*FileSystemStateResponse) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FileSystemStateResponse) GetMsg() string {
+	if x != nil {
+		return x.Msg
+	}
+	return ""
+}
+
+func (x *FileSystemStateResponse) GetState() FileSystemState {
+	if x != nil {
+		return x.State
+	}
+	return FileSystemState_INVALID
+}
+
+func (x *FileSystemStateResponse) GetMissingParts() []string {
+	if x != nil {
+		return x.MissingParts
+	}
+	return nil
+}
+
+func (x *FileSystemStateResponse) GetExtraParts() []string {
+	if x != nil {
+		return x.ExtraParts
+	}
+	return nil
+}
+
 type FsInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Signature     string   `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
-	SpAddress     string   `protobuf:"bytes,2,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
-	SignedAddress string   `protobuf:"bytes,3,opt,name=signed_address,json=signedAddress,proto3" json:"signed_address,omitempty"`
-	Network       string   `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
-	NewFs         []string `protobuf:"bytes,5,rep,name=new_fs,json=newFs,proto3" json:"new_fs,omitempty"`
-	Nonce         uint32   `protobuf:"varint,6,opt,name=nonce,proto3" json:"nonce,omitempty"`
-	Storage       uint32   `protobuf:"varint,7,opt,name=storage,proto3" json:"storage,omitempty"`
+	Signature     string     `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	SpAddress     string     `protobuf:"bytes,2,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
+	SignedAddress string     `protobuf:"bytes,3,opt,name=signed_address,json=signedAddress,proto3" json:"signed_address,omitempty"`
+	Network       string     `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
+	NewFs         []string   `protobuf:"bytes,5,rep,name=new_fs,json=newFs,proto3" json:"new_fs,omitempty"`
+	Nonce         uint32     `protobuf:"varint,6,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Storage       uint32     `protobuf:"varint,7,opt,name=storage,proto3" json:"storage,omitempty"`
+	Sign          *Signature `protobuf:"bytes,8,opt,name=sign,proto3" json:"sign,omitempty"` // payload: signature
 }
 
 func (x *FsInfo) Reset() {
 	*x = FsInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_upload_proto_msgTypes[1]
+		mi := &file_upload_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -97,7 +403,7 @@ func (x *FsInfo) String() string {
 func (*FsInfo) ProtoMessage() {}
 
 func (x *FsInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_upload_proto_msgTypes[1]
+	mi := &file_upload_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -110,7 +416,7 @@ func (x *FsInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FsInfo.ProtoReflect.Descriptor instead.
 func (*FsInfo) Descriptor() ([]byte, []int) {
-	return file_upload_proto_rawDescGZIP(), []int{1}
+	return file_upload_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *FsInfo) GetSignature() string {
@@ -134,64 +440,590 @@ func (x *FsInfo) GetSignedAddress() string {
 	return ""
 }
 
-func (x *FsInfo) GetNetwork() string {
+func (x *FsInfo) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *FsInfo) GetNewFs() []string {
+	if x != nil {
+		return x.NewFs
+	}
+	return nil
+}
+
+func (x *FsInfo) GetNonce() uint32 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *FsInfo) GetStorage() uint32 {
+	if x != nil {
+		return x.Storage
+	}
+	return 0
+}
+
+func (x *FsInfo) GetSign() *Signature {
+	if x != nil {
+		return x.Sign
+	}
+	return nil
+}
+
+type UploadRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FileSize      uint32     `protobuf:"varint,1,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	FileName      string     `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	SpAddress     string     `protobuf:"bytes,3,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
+	SignedAddress string     `protobuf:"bytes,4,opt,name=signed_address,json=signedAddress,proto3" json:"signed_address,omitempty"`
+	Network       string     `protobuf:"bytes,5,opt,name=network,proto3" json:"network,omitempty"`
+	ChunkData     []byte     `protobuf:"bytes,6,opt,name=chunk_data,json=chunkData,proto3" json:"chunk_data,omitempty"`
+	Sign          *Signature `protobuf:"bytes,7,opt,name=sign,proto3" json:"sign,omitempty"` // payload: sp_address + file_size (4 bytes, big endian), passed in the first message only
+}
+
+func (x *UploadRequest) Reset() {
+	*x = UploadRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadRequest) ProtoMessage() {}
+
+func (x *UploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadRequest.ProtoReflect.Descriptor instead.
+func (*UploadRequest) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UploadRequest) GetFileSize() uint32 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *UploadRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *UploadRequest) GetSpAddress() string {
+	if x != nil {
+		return x.SpAddress
+	}
+	return ""
+}
+
+func (x *UploadRequest) GetSignedAddress() string {
+	if x != nil {
+		return x.SignedAddress
+	}
+	return ""
+}
+
+func (x *UploadRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *UploadRequest) GetChunkData() []byte {
+	if x != nil {
+		return x.ChunkData
+	}
+	return nil
+}
+
+func (x *UploadRequest) GetSign() *Signature {
+	if x != nil {
+		return x.Sign
+	}
+	return nil
+}
+
+// TrafficInfo is a receipt of traffic paid by storage provider, node serves parts until paid traffic is used up.
+// sign = sign(sha256(node_address (20 bytes) + network + paid_traffic (8 bytes, big endian))).
+// In GetTrafficInfo response sign is the last accepted receipt and uploaded/downloaded are bytes counted by node.
+type TrafficInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sign        []byte     `protobuf:"bytes,1,opt,name=sign,proto3" json:"sign,omitempty"`
+	Network     string     `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	SpAddress   string     `protobuf:"bytes,3,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
+	PaidTraffic uint64     `protobuf:"varint,4,opt,name=paid_traffic,json=paidTraffic,proto3" json:"paid_traffic,omitempty"`
+	Uploaded    uint64     `protobuf:"varint,5,opt,name=uploaded,proto3" json:"uploaded,omitempty"`
+	Downloaded  uint64     `protobuf:"varint,6,opt,name=downloaded,proto3" json:"downloaded,omitempty"`
+	Auth        *Signature `protobuf:"bytes,7,opt,name=auth,proto3" json:"auth,omitempty"` // payload: sp_address, required in GetTrafficInfo request only
+}
+
+func (x *TrafficInfo) Reset() {
+	*x = TrafficInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrafficInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficInfo) ProtoMessage() {}
+
+func (x *TrafficInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficInfo.ProtoReflect.Descriptor instead.
+func (*TrafficInfo) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TrafficInfo) GetSign() []byte {
+	if x != nil {
+		return x.Sign
+	}
+	return nil
+}
+
+func (x *TrafficInfo) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *TrafficInfo) GetSpAddress() string {
+	if x != nil {
+		return x.SpAddress
+	}
+	return ""
+}
+
+func (x *TrafficInfo) GetPaidTraffic() uint64 {
+	if x != nil {
+		return x.PaidTraffic
+	}
+	return 0
+}
+
+func (x *TrafficInfo) GetUploaded() uint64 {
+	if x != nil {
+		return x.Uploaded
+	}
+	return 0
+}
+
+func (x *TrafficInfo) GetDownloaded() uint64 {
+	if x != nil {
+		return x.Downloaded
+	}
+	return 0
+}
+
+func (x *TrafficInfo) GetAuth() *Signature {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+type DownloadRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FileNames      []string     `protobuf:"bytes,1,rep,name=file_names,json=fileNames,proto3" json:"file_names,omitempty"`
+	SpAddress      string       `protobuf:"bytes,2,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
+	SignedAddress  string       `protobuf:"bytes,3,opt,name=signed_address,json=signedAddress,proto3" json:"signed_address,omitempty"`
+	Network        string       `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
+	Sign           *Signature   `protobuf:"bytes,5,opt,name=sign,proto3" json:"sign,omitempty"`                                           // payload: sp_address + file_names
+	UpdatedTraffic *TrafficInfo `protobuf:"bytes,6,opt,name=updated_traffic,json=updatedTraffic,proto3" json:"updated_traffic,omitempty"` // optional, the latest receipt of paid traffic
+}
+
+func (x *DownloadRequest) Reset() {
+	*x = DownloadRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DownloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadRequest) ProtoMessage() {}
+
+func (x *DownloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadRequest.ProtoReflect.Descriptor instead.
+func (*DownloadRequest) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DownloadRequest) GetFileNames() []string {
+	if x != nil {
+		return x.FileNames
+	}
+	return nil
+}
+
+func (x *DownloadRequest) GetSpAddress() string {
+	if x != nil {
+		return x.SpAddress
+	}
+	return ""
+}
+
+func (x *DownloadRequest) GetSignedAddress() string {
+	if x != nil {
+		return x.SignedAddress
+	}
+	return ""
+}
+
+func (x *DownloadRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *DownloadRequest) GetSign() *Signature {
+	if x != nil {
+		return x.Sign
+	}
+	return nil
+}
+
+func (x *DownloadRequest) GetUpdatedTraffic() *TrafficInfo {
+	if x != nil {
+		return x.UpdatedTraffic
+	}
+	return nil
+}
+
+type DownloadResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChunkData []byte `protobuf:"bytes,1,opt,name=chunk_data,json=chunkData,proto3" json:"chunk_data,omitempty"`
+}
+
+func (x *DownloadResponse) Reset() {
+	*x = DownloadResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DownloadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadResponse) ProtoMessage() {}
+
+func (x *DownloadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadResponse.ProtoReflect.Descriptor instead.
+func (*DownloadResponse) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DownloadResponse) GetChunkData() []byte {
+	if x != nil {
+		return x.ChunkData
+	}
+	return nil
+}
+
+// DelegationToken is issued by storage provider and allows gateway to download its parts until expires_at (unix timestamp).
+// signature = sign(sha256(sp_address + gateway_address + network + file_names + expires_at (8 bytes, big endian))).
+type DelegationToken struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpAddress      string   `protobuf:"bytes,1,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
+	GatewayAddress string   `protobuf:"bytes,2,opt,name=gateway_address,json=gatewayAddress,proto3" json:"gateway_address,omitempty"`
+	Network        string   `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	FileNames      []string `protobuf:"bytes,4,rep,name=file_names,json=fileNames,proto3" json:"file_names,omitempty"` // empty list allows downloading any part
+	ExpiresAt      int64    `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Signature      string   `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *DelegationToken) Reset() {
+	*x = DelegationToken{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DelegationToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DelegationToken) ProtoMessage() {}
+
+func (x *DelegationToken) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DelegationToken.ProtoReflect.Descriptor instead.
+func (*DelegationToken) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DelegationToken) GetSpAddress() string {
+	if x != nil {
+		return x.SpAddress
+	}
+	return ""
+}
+
+func (x *DelegationToken) GetGatewayAddress() string {
+	if x != nil {
+		return x.GatewayAddress
+	}
+	return ""
+}
+
+func (x *DelegationToken) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *DelegationToken) GetFileNames() []string {
+	if x != nil {
+		return x.FileNames
+	}
+	return nil
+}
+
+func (x *DelegationToken) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *DelegationToken) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+type GatewayDownloadRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FileNames            []string         `protobuf:"bytes,1,rep,name=file_names,json=fileNames,proto3" json:"file_names,omitempty"`
+	SpAddress            string           `protobuf:"bytes,2,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
+	GatewayAddress       string           `protobuf:"bytes,3,opt,name=gateway_address,json=gatewayAddress,proto3" json:"gateway_address,omitempty"`
+	SignedGatewayAddress string           `protobuf:"bytes,4,opt,name=signed_gateway_address,json=signedGatewayAddress,proto3" json:"signed_gateway_address,omitempty"`
+	Network              string           `protobuf:"bytes,5,opt,name=network,proto3" json:"network,omitempty"`
+	Sign                 *Signature       `protobuf:"bytes,6,opt,name=sign,proto3" json:"sign,omitempty"` // signed by gateway, payload: sp_address + file_names
+	Token                *DelegationToken `protobuf:"bytes,7,opt,name=token,proto3" json:"token,omitempty"`
+	UpdatedTraffic       *TrafficInfo     `protobuf:"bytes,8,opt,name=updated_traffic,json=updatedTraffic,proto3" json:"updated_traffic,omitempty"` // optional, the latest receipt of storage provider's paid traffic
+}
+
+func (x *GatewayDownloadRequest) Reset() {
+	*x = GatewayDownloadRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_upload_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GatewayDownloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GatewayDownloadRequest) ProtoMessage() {}
+
+func (x *GatewayDownloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GatewayDownloadRequest.ProtoReflect.Descriptor instead.
+func (*GatewayDownloadRequest) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GatewayDownloadRequest) GetFileNames() []string {
+	if x != nil {
+		return x.FileNames
+	}
+	return nil
+}
+
+func (x *GatewayDownloadRequest) GetSpAddress() string {
+	if x != nil {
+		return x.SpAddress
+	}
+	return ""
+}
+
+func (x *GatewayDownloadRequest) GetGatewayAddress() string {
+	if x != nil {
+		return x.GatewayAddress
+	}
+	return ""
+}
+
+func (x *GatewayDownloadRequest) GetSignedGatewayAddress() string {
+	if x != nil {
+		return x.SignedGatewayAddress
+	}
+	return ""
+}
+
+func (x *GatewayDownloadRequest) GetNetwork() string {
 	if x != nil {
 		return x.Network
 	}
 	return ""
 }
 
-func (x *FsInfo) GetNewFs() []string {
+func (x *GatewayDownloadRequest) GetSign() *Signature {
 	if x != nil {
-		return x.NewFs
+		return x.Sign
 	}
 	return nil
 }
 
-func (x *FsInfo) GetNonce() uint32 {
+func (x *GatewayDownloadRequest) GetToken() *DelegationToken {
 	if x != nil {
-		return x.Nonce
+		return x.Token
 	}
-	return 0
+	return nil
 }
 
-func (x *FsInfo) GetStorage() uint32 {
+func (x *GatewayDownloadRequest) GetUpdatedTraffic() *TrafficInfo {
 	if x != nil {
-		return x.Storage
+		return x.UpdatedTraffic
 	}
-	return 0
+	return nil
 }
 
-type UploadRequest struct {
+type FsBackupInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FileSize      uint32 `protobuf:"varint,1,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
-	FileName      string `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
-	SpAddress     string `protobuf:"bytes,3,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
-	SignedAddress string `protobuf:"bytes,4,opt,name=signed_address,json=signedAddress,proto3" json:"signed_address,omitempty"`
-	Network       string `protobuf:"bytes,5,opt,name=network,proto3" json:"network,omitempty"`
-	ChunkData     []byte `protobuf:"bytes,6,opt,name=chunk_data,json=chunkData,proto3" json:"chunk_data,omitempty"`
+	SpAddress string `protobuf:"bytes,1,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
+	Network   string `protobuf:"bytes,2,opt,name=network,proto3" json:"network,omitempty"`
+	Version   uint32 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Size      uint32 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Hash      string `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`           // hex encoded sha256 of fs backup
+	Signature string `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"` // sign(sha256(sp_address + network + version + size + hash))
 }
 
-func (x *UploadRequest) Reset() {
-	*x = UploadRequest{}
+func (x *FsBackupInfo) Reset() {
+	*x = FsBackupInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_upload_proto_msgTypes[2]
+		mi := &file_upload_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *UploadRequest) String() string {
+func (x *FsBackupInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadRequest) ProtoMessage() {}
+func (*FsBackupInfo) ProtoMessage() {}
 
-func (x *UploadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_upload_proto_msgTypes[2]
+func (x *FsBackupInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -202,81 +1034,80 @@ func (x *UploadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadRequest.ProtoReflect.Descriptor instead.
-func (*UploadRequest) Descriptor() ([]byte, []int) {
-	return file_upload_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use FsBackupInfo.ProtoReflect.Descriptor instead.
+func (*FsBackupInfo) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *UploadRequest) GetFileSize() uint32 {
+func (x *FsBackupInfo) GetSpAddress() string {
 	if x != nil {
-		return x.FileSize
+		return x.SpAddress
 	}
-	return 0
+	return ""
 }
 
-func (x *UploadRequest) GetFileName() string {
+func (x *FsBackupInfo) GetNetwork() string {
 	if x != nil {
-		return x.FileName
+		return x.Network
 	}
 	return ""
 }
 
-func (x *UploadRequest) GetSpAddress() string {
+func (x *FsBackupInfo) GetVersion() uint32 {
 	if x != nil {
-		return x.SpAddress
+		return x.Version
 	}
-	return ""
+	return 0
 }
 
-func (x *UploadRequest) GetSignedAddress() string {
+func (x *FsBackupInfo) GetSize() uint32 {
 	if x != nil {
-		return x.SignedAddress
+		return x.Size
 	}
-	return ""
+	return 0
 }
 
-func (x *UploadRequest) GetNetwork() string {
+func (x *FsBackupInfo) GetHash() string {
 	if x != nil {
-		return x.Network
+		return x.Hash
 	}
 	return ""
 }
 
-func (x *UploadRequest) GetChunkData() []byte {
+func (x *FsBackupInfo) GetSignature() string {
 	if x != nil {
-		return x.ChunkData
+		return x.Signature
 	}
-	return nil
+	return ""
 }
 
-type DownloadRequest struct {
+type UploadFsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FileNames     []string `protobuf:"bytes,1,rep,name=file_names,json=fileNames,proto3" json:"file_names,omitempty"`
-	SpAddress     string   `protobuf:"bytes,2,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
-	SignedAddress string   `protobuf:"bytes,3,opt,name=signed_address,json=signedAddress,proto3" json:"signed_address,omitempty"`
-	Network       string   `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
+	Info      *FsBackupInfo `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"` // passed in the first message only
+	ChunkData []byte        `protobuf:"bytes,2,opt,name=chunk_data,json=chunkData,proto3" json:"chunk_data,omitempty"`
+	Sign      *Signature    `protobuf:"bytes,3,opt,name=sign,proto3" json:"sign,omitempty"` // payload: info.signature, passed in the first message only
 }
 
-func (x *DownloadRequest) Reset() {
-	*x = DownloadRequest{}
+func (x *UploadFsRequest) Reset() {
+	*x = UploadFsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_upload_proto_msgTypes[3]
+		mi := &file_upload_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DownloadRequest) String() string {
+func (x *UploadFsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadRequest) ProtoMessage() {}
+func (*UploadFsRequest) ProtoMessage() {}
 
-func (x *DownloadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_upload_proto_msgTypes[3]
+func (x *UploadFsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -287,64 +1118,60 @@ func (x *DownloadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadRequest.ProtoReflect.Descriptor instead.
-func (*DownloadRequest) Descriptor() ([]byte, []int) {
-	return file_upload_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use UploadFsRequest.ProtoReflect.Descriptor instead.
+func (*UploadFsRequest) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *DownloadRequest) GetFileNames() []string {
+func (x *UploadFsRequest) GetInfo() *FsBackupInfo {
 	if x != nil {
-		return x.FileNames
+		return x.Info
 	}
 	return nil
 }
 
-func (x *DownloadRequest) GetSpAddress() string {
-	if x != nil {
-		return x.SpAddress
-	}
-	return ""
-}
-
-func (x *DownloadRequest) GetSignedAddress() string {
+func (x *UploadFsRequest) GetChunkData() []byte {
 	if x != nil {
-		return x.SignedAddress
+		return x.ChunkData
 	}
-	return ""
+	return nil
 }
 
-func (x *DownloadRequest) GetNetwork() string {
+func (x *UploadFsRequest) GetSign() *Signature {
 	if x != nil {
-		return x.Network
+		return x.Sign
 	}
-	return ""
+	return nil
 }
 
-type DownloadResponse struct {
+type DownloadFsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ChunkData []byte `protobuf:"bytes,1,opt,name=chunk_data,json=chunkData,proto3" json:"chunk_data,omitempty"`
+	SpAddress     string     `protobuf:"bytes,1,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
+	SignedAddress string     `protobuf:"bytes,2,opt,name=signed_address,json=signedAddress,proto3" json:"signed_address,omitempty"`
+	Network       string     `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	Sign          *Signature `protobuf:"bytes,4,opt,name=sign,proto3" json:"sign,omitempty"` // payload: sp_address
 }
 
-func (x *DownloadResponse) Reset() {
-	*x = DownloadResponse{}
+func (x *DownloadFsRequest) Reset() {
+	*x = DownloadFsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_upload_proto_msgTypes[4]
+		mi := &file_upload_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DownloadResponse) String() string {
+func (x *DownloadFsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadResponse) ProtoMessage() {}
+func (*DownloadFsRequest) ProtoMessage() {}
 
-func (x *DownloadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_upload_proto_msgTypes[4]
+func (x *DownloadFsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -355,47 +1182,65 @@ func (x *DownloadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadResponse.ProtoReflect.Descriptor instead.
-func (*DownloadResponse) Descriptor() ([]byte, []int) {
-	return file_upload_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use DownloadFsRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFsRequest) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *DownloadResponse) GetChunkData() []byte {
+func (x *DownloadFsRequest) GetSpAddress() string {
 	if x != nil {
-		return x.ChunkData
+		return x.SpAddress
+	}
+	return ""
+}
+
+func (x *DownloadFsRequest) GetSignedAddress() string {
+	if x != nil {
+		return x.SignedAddress
+	}
+	return ""
+}
+
+func (x *DownloadFsRequest) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *DownloadFsRequest) GetSign() *Signature {
+	if x != nil {
+		return x.Sign
 	}
 	return nil
 }
 
-type GatewayDownloadRequest struct {
+type DownloadFsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FileNames            []string `protobuf:"bytes,1,rep,name=file_names,json=fileNames,proto3" json:"file_names,omitempty"`
-	SpAddress            string   `protobuf:"bytes,2,opt,name=sp_address,json=spAddress,proto3" json:"sp_address,omitempty"`
-	GatewayAddress       string   `protobuf:"bytes,3,opt,name=gateway_address,json=gatewayAddress,proto3" json:"gateway_address,omitempty"`
-	SignedGatewayAddress string   `protobuf:"bytes,4,opt,name=signed_gateway_address,json=signedGatewayAddress,proto3" json:"signed_gateway_address,omitempty"`
-	Network              string   `protobuf:"bytes,5,opt,name=network,proto3" json:"network,omitempty"`
+	Info      *FsBackupInfo `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"` // passed in the first message only
+	ChunkData []byte        `protobuf:"bytes,2,opt,name=chunk_data,json=chunkData,proto3" json:"chunk_data,omitempty"`
 }
 
-func (x *GatewayDownloadRequest) Reset() {
-	*x = GatewayDownloadRequest{}
+func (x *DownloadFsResponse) Reset() {
+	*x = DownloadFsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_upload_proto_msgTypes[5]
+		mi := &file_upload_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GatewayDownloadRequest) String() string {
+func (x *DownloadFsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GatewayDownloadRequest) ProtoMessage() {}
+func (*DownloadFsResponse) ProtoMessage() {}
 
-func (x *GatewayDownloadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_upload_proto_msgTypes[5]
+func (x *DownloadFsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -406,44 +1251,23 @@ func (x *GatewayDownloadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GatewayDownloadRequest.ProtoReflect.Descriptor instead.
-func (*GatewayDownloadRequest) Descriptor() ([]byte, []int) {
-	return file_upload_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use DownloadFsResponse.ProtoReflect.Descriptor instead.
+func (*DownloadFsResponse) Descriptor() ([]byte, []int) {
+	return file_upload_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *GatewayDownloadRequest) GetFileNames() []string {
+func (x *DownloadFsResponse) GetInfo() *FsBackupInfo {
 	if x != nil {
-		return x.FileNames
+		return x.Info
 	}
 	return nil
 }
 
-func (x *GatewayDownloadRequest) GetSpAddress() string {
-	if x != nil {
-		return x.SpAddress
-	}
-	return ""
-}
-
-func (x *GatewayDownloadRequest) GetGatewayAddress() string {
-	if x != nil {
-		return x.GatewayAddress
-	}
-	return ""
-}
-
-func (x *GatewayDownloadRequest) GetSignedGatewayAddress() string {
-	if x != nil {
-		return x.SignedGatewayAddress
-	}
-	return ""
-}
-
-func (x *GatewayDownloadRequest) GetNetwork() string {
+func (x *DownloadFsResponse) GetChunkData() []byte {
 	if x != nil {
-		return x.Network
+		return x.ChunkData
 	}
-	return ""
+	return nil
 }
 
 var File_upload_proto protoreflect.FileDescriptor
@@ -452,75 +1276,201 @@ var file_upload_proto_rawDesc = []byte{
 	0x0a, 0x0c, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05,
 	0x6c, 0x6f, 0x61, 0x64, 0x73, 0x22, 0x1c, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
 	0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x6d, 0x73, 0x67, 0x22, 0xcd, 0x01, 0x0a, 0x06, 0x46, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1c,
-	0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1d, 0x0a, 0x0a,
+	0x6d, 0x73, 0x67, 0x22, 0x26, 0x0a, 0x0c, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x22, 0x3c, 0x0a, 0x05, 0x4e,
+	0x6f, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22, 0x80, 0x01, 0x0a, 0x09, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12,
+	0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x73, 0x69, 0x67,
+	0x6e, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x22, 0x9f, 0x01, 0x0a,
+	0x17, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x73, 0x67, 0x12, 0x2c, 0x0a, 0x05, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x6c, 0x6f, 0x61, 0x64,
+	0x73, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x69, 0x73, 0x73,
+	0x69, 0x6e, 0x67, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0c, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x74, 0x73, 0x12, 0x1f, 0x0a,
+	0x0b, 0x65, 0x78, 0x74, 0x72, 0x61, 0x5f, 0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0a, 0x65, 0x78, 0x74, 0x72, 0x61, 0x50, 0x61, 0x72, 0x74, 0x73, 0x22, 0xf3,
+	0x01, 0x0a, 0x06, 0x46, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64,
+	0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a,
+	0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x15, 0x0a, 0x06, 0x6e, 0x65, 0x77, 0x5f, 0x66,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x65, 0x77, 0x46, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6e,
+	0x6f, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x12, 0x24,
+	0x0a, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c,
+	0x6f, 0x61, 0x64, 0x73, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04,
+	0x73, 0x69, 0x67, 0x6e, 0x22, 0xee, 0x01, 0x0a, 0x0d, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x53,
+	0x69, 0x7a, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x25, 0x0a, 0x0e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72,
+	0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x12,
+	0x24, 0x0a, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52,
+	0x04, 0x73, 0x69, 0x67, 0x6e, 0x22, 0xdf, 0x01, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69,
+	0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77,
+	0x6f, 0x72, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x61, 0x69, 0x64, 0x5f, 0x74, 0x72, 0x61, 0x66, 0x66,
+	0x69, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x70, 0x61, 0x69, 0x64, 0x54, 0x72,
+	0x61, 0x66, 0x66, 0x69, 0x63, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x65,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x65,
+	0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65,
+	0x64, 0x12, 0x24, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x22, 0xf3, 0x01, 0x0a, 0x0f, 0x44, 0x6f, 0x77, 0x6e,
+	0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x66,
+	0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x09, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70,
+	0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x73, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x69, 0x67,
+	0x6e, 0x65, 0x64, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x24, 0x0a, 0x04, 0x73, 0x69,
+	0x67, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73,
+	0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x73, 0x69, 0x67, 0x6e,
+	0x12, 0x3b, 0x0a, 0x0f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x72, 0x61, 0x66,
+	0x66, 0x69, 0x63, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6c, 0x6f, 0x61, 0x64,
+	0x73, 0x2e, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0e, 0x75,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x22, 0x31, 0x0a,
+	0x10, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61,
+	0x22, 0xcf, 0x01, 0x0a, 0x0f, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x5f, 0x61,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x67, 0x61,
+	0x74, 0x65, 0x77, 0x61, 0x79, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e,
+	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73,
+	0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72,
+	0x65, 0x73, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x22, 0xe0, 0x02, 0x0a, 0x16, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x44, 0x6f,
+	0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a,
+	0x0a, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a,
 	0x73, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x73, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73,
-	0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x41, 0x64, 0x64, 0x72, 0x65,
-	0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x15, 0x0a, 0x06,
-	0x6e, 0x65, 0x77, 0x5f, 0x66, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x65,
-	0x77, 0x46, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x0d, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x6f,
-	0x72, 0x61, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x73, 0x74, 0x6f, 0x72,
-	0x61, 0x67, 0x65, 0x22, 0xc8, 0x01, 0x0a, 0x0d, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x69,
-	0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69,
-	0x7a, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12,
-	0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x25,
-	0x0a, 0x0e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x41, 0x64,
+	0x52, 0x09, 0x73, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x41, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x47, 0x61, 0x74, 0x65,
+	0x77, 0x61, 0x79, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x12, 0x24, 0x0a, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x12, 0x2c, 0x0a, 0x05, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x6c, 0x6f, 0x61, 0x64,
+	0x73, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x3b, 0x0a, 0x0f, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69,
+	0x63, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0e, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x54, 0x72,
+	0x61, 0x66, 0x66, 0x69, 0x63, 0x22, 0xa7, 0x01, 0x0a, 0x0c, 0x46, 0x73, 0x42, 0x61, 0x63, 0x6b,
+	0x75, 0x70, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41, 0x64,
 	0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
-	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12,
-	0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x22, 0x90,
-	0x01, 0x0a, 0x0f, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65,
-	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
-	0x12, 0x25, 0x0a, 0x0e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65,
-	0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64,
-	0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f,
-	0x72, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72,
-	0x6b, 0x22, 0x31, 0x0a, 0x10, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x64,
-	0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b,
-	0x44, 0x61, 0x74, 0x61, 0x22, 0xcf, 0x01, 0x0a, 0x16, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
-	0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x1d,
-	0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x27, 0x0a,
-	0x0f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x41,
-	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64,
-	0x5f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x47, 0x61,
-	0x74, 0x65, 0x77, 0x61, 0x79, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07,
-	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e,
-	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x32, 0x84, 0x02, 0x0a, 0x0b, 0x4e, 0x6f, 0x64, 0x65, 0x53,
-	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x35, 0x0a, 0x0a, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64,
-	0x46, 0x69, 0x6c, 0x65, 0x12, 0x14, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x55, 0x70, 0x6c,
-	0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x6c, 0x6f, 0x61,
-	0x64, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x2a, 0x0a,
-	0x08, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x73, 0x12, 0x0d, 0x2e, 0x6c, 0x6f, 0x61, 0x64,
-	0x73, 0x2e, 0x46, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x0f, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73,
-	0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0c, 0x44, 0x6f, 0x77,
-	0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x16, 0x2e, 0x6c, 0x6f, 0x61, 0x64,
-	0x73, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x17, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f,
-	0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4f, 0x0a, 0x13,
-	0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46,
-	0x69, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x47, 0x61, 0x74, 0x65,
-	0x77, 0x61, 0x79, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73,
+	0x68, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22,
+	0x7f, 0x0a, 0x0f, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x27, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x46, 0x73, 0x42, 0x61, 0x63, 0x6b, 0x75,
+	0x70, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
+	0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x12, 0x24, 0x0a, 0x04, 0x73, 0x69,
+	0x67, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73,
+	0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x73, 0x69, 0x67, 0x6e,
+	0x22, 0x99, 0x01, 0x0a, 0x11, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x5f, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73,
+	0x69, 0x67, 0x6e, 0x65, 0x64, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e,
+	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x24, 0x0a, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x22, 0x5c, 0x0a, 0x12,
+	0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x27, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x46, 0x73, 0x42, 0x61, 0x63, 0x6b, 0x75,
+	0x70, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x12, 0x1d, 0x0a, 0x0a, 0x63,
+	0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x2a, 0x43, 0x0a, 0x0f, 0x46, 0x69,
+	0x6c, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a,
+	0x07, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x43,
+	0x54, 0x55, 0x41, 0x4c, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x49, 0x4e, 0x43, 0x4f, 0x4d, 0x50,
+	0x4c, 0x45, 0x54, 0x45, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x4f, 0x4c, 0x44, 0x10, 0x03, 0x32,
+	0xf8, 0x03, 0x0a, 0x0b, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x2d, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x13, 0x2e, 0x6c, 0x6f,
+	0x61, 0x64, 0x73, 0x2e, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x0c, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x38,
+	0x0a, 0x0e, 0x47, 0x65, 0x74, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x49, 0x6e, 0x66, 0x6f,
+	0x12, 0x12, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63,
+	0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x12, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x54, 0x72, 0x61,
+	0x66, 0x66, 0x69, 0x63, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x35, 0x0a, 0x0a, 0x55, 0x70, 0x6c, 0x6f,
+	0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x14, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x55,
+	0x70, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x6c,
+	0x6f, 0x61, 0x64, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x12,
+	0x39, 0x0a, 0x08, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x73, 0x12, 0x0d, 0x2e, 0x6c, 0x6f,
+	0x61, 0x64, 0x73, 0x2e, 0x46, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x1e, 0x2e, 0x6c, 0x6f, 0x61,
+	0x64, 0x73, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0c, 0x44, 0x6f,
+	0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x16, 0x2e, 0x6c, 0x6f, 0x61,
+	0x64, 0x73, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x1a, 0x17, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c,
-	0x6f, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x07, 0x5a,
-	0x05, 0x2e, 0x2e, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x4f, 0x0a,
+	0x13, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64,
+	0x46, 0x69, 0x6c, 0x65, 0x12, 0x1d, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x47, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x44, 0x6f, 0x77, 0x6e,
+	0x6c, 0x6f, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x35,
+	0x0a, 0x08, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x53, 0x12, 0x16, 0x2e, 0x6c, 0x6f, 0x61,
+	0x64, 0x73, 0x2e, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x43, 0x0a, 0x0a, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61,
+	0x64, 0x46, 0x53, 0x12, 0x18, 0x2e, 0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x44, 0x6f, 0x77, 0x6e,
+	0x6c, 0x6f, 0x61, 0x64, 0x46, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
+	0x6c, 0x6f, 0x61, 0x64, 0x73, 0x2e, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x07, 0x5a, 0x05, 0x2e, 0x2e,
+	0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -535,29 +1485,62 @@ func file_upload_proto_rawDescGZIP() []byte {
 	return file_upload_proto_rawDescData
 }
 
-var file_upload_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_upload_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_upload_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_upload_proto_goTypes = []interface{}{
-	(*Response)(nil),               // 0: loads.Response
-	(*FsInfo)(nil),                 // 1: loads.FsInfo
-	(*UploadRequest)(nil),          // 2: loads.UploadRequest
-	(*DownloadRequest)(nil),        // 3: loads.DownloadRequest
-	(*DownloadResponse)(nil),       // 4: loads.DownloadResponse
-	(*GatewayDownloadRequest)(nil), // 5: loads.GatewayDownloadRequest
+	(FileSystemState)(0),            // 0: loads.FileSystemState
+	(*Response)(nil),                // 1: loads.Response
+	(*NonceRequest)(nil),            // 2: loads.NonceRequest
+	(*Nonce)(nil),                   // 3: loads.Nonce
+	(*Signature)(nil),               // 4: loads.Signature
+	(*FileSystemStateResponse)(nil), // 5: loads.FileSystemStateResponse
+	(*FsInfo)(nil),                  // 6: loads.FsInfo
+	(*UploadRequest)(nil),           // 7: loads.UploadRequest
+	(*TrafficInfo)(nil),             // 8: loads.TrafficInfo
+	(*DownloadRequest)(nil),         // 9: loads.DownloadRequest
+	(*DownloadResponse)(nil),        // 10: loads.DownloadResponse
+	(*DelegationToken)(nil),         // 11: loads.DelegationToken
+	(*GatewayDownloadRequest)(nil),  // 12: loads.GatewayDownloadRequest
+	(*FsBackupInfo)(nil),            // 13: loads.FsBackupInfo
+	(*UploadFsRequest)(nil),         // 14: loads.UploadFsRequest
+	(*DownloadFsRequest)(nil),       // 15: loads.DownloadFsRequest
+	(*DownloadFsResponse)(nil),      // 16: loads.DownloadFsResponse
 }
 var file_upload_proto_depIdxs = []int32{
-	2, // 0: loads.NodeService.UploadFile:input_type -> loads.UploadRequest
-	1, // 1: loads.NodeService.UpdateFs:input_type -> loads.FsInfo
-	3, // 2: loads.NodeService.DownloadFile:input_type -> loads.DownloadRequest
-	5, // 3: loads.NodeService.GatewayDownloadFile:input_type -> loads.GatewayDownloadRequest
-	0, // 4: loads.NodeService.UploadFile:output_type -> loads.Response
-	0, // 5: loads.NodeService.UpdateFs:output_type -> loads.Response
-	4, // 6: loads.NodeService.DownloadFile:output_type -> loads.DownloadResponse
-	4, // 7: loads.NodeService.GatewayDownloadFile:output_type -> loads.DownloadResponse
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: loads.FileSystemStateResponse.state:type_name -> loads.FileSystemState
+	4,  // 1: loads.FsInfo.sign:type_name -> loads.Signature
+	4,  // 2: loads.UploadRequest.sign:type_name -> loads.Signature
+	4,  // 3: loads.TrafficInfo.auth:type_name -> loads.Signature
+	4,  // 4: loads.DownloadRequest.sign:type_name -> loads.Signature
+	8,  // 5: loads.DownloadRequest.updated_traffic:type_name -> loads.TrafficInfo
+	4,  // 6: loads.GatewayDownloadRequest.sign:type_name -> loads.Signature
+	11, // 7: loads.GatewayDownloadRequest.token:type_name -> loads.DelegationToken
+	8,  // 8: loads.GatewayDownloadRequest.updated_traffic:type_name -> loads.TrafficInfo
+	13, // 9: loads.UploadFsRequest.info:type_name -> loads.FsBackupInfo
+	4,  // 10: loads.UploadFsRequest.sign:type_name -> loads.Signature
+	4,  // 11: loads.DownloadFsRequest.sign:type_name -> loads.Signature
+	13, // 12: loads.DownloadFsResponse.info:type_name -> loads.FsBackupInfo
+	2,  // 13: loads.NodeService.GetNonce:input_type -> loads.NonceRequest
+	8,  // 14: loads.NodeService.GetTrafficInfo:input_type -> loads.TrafficInfo
+	7,  // 15: loads.NodeService.UploadFile:input_type -> loads.UploadRequest
+	6,  // 16: loads.NodeService.UpdateFs:input_type -> loads.FsInfo
+	9,  // 17: loads.NodeService.DownloadFile:input_type -> loads.DownloadRequest
+	12, // 18: loads.NodeService.GatewayDownloadFile:input_type -> loads.GatewayDownloadRequest
+	14, // 19: loads.NodeService.UploadFS:input_type -> loads.UploadFsRequest
+	15, // 20: loads.NodeService.DownloadFS:input_type -> loads.DownloadFsRequest
+	3,  // 21: loads.NodeService.GetNonce:output_type -> loads.Nonce
+	8,  // 22: loads.NodeService.GetTrafficInfo:output_type -> loads.TrafficInfo
+	1,  // 23: loads.NodeService.UploadFile:output_type -> loads.Response
+	5,  // 24: loads.NodeService.UpdateFs:output_type -> loads.FileSystemStateResponse
+	10, // 25: loads.NodeService.DownloadFile:output_type -> loads.DownloadResponse
+	10, // 26: loads.NodeService.GatewayDownloadFile:output_type -> loads.DownloadResponse
+	1,  // 27: loads.NodeService.UploadFS:output_type -> loads.Response
+	16, // 28: loads.NodeService.DownloadFS:output_type -> loads.DownloadFsResponse
+	21, // [21:29] is the sub-list for method output_type
+	13, // [13:21] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_upload_proto_init() }
@@ -579,7 +1562,7 @@ func file_upload_proto_init() {
 			}
 		}
 		file_upload_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FsInfo); i {
+			switch v := v.(*NonceRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -591,7 +1574,7 @@ func file_upload_proto_init() {
 			}
 		}
 		file_upload_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UploadRequest); i {
+			switch v := v.(*Nonce); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -603,7 +1586,7 @@ func file_upload_proto_init() {
 			}
 		}
 		file_upload_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DownloadRequest); i {
+			switch v := v.(*Signature); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -615,7 +1598,7 @@ func file_upload_proto_init() {
 			}
 		}
 		file_upload_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DownloadResponse); i {
+			switch v := v.(*FileSystemStateResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -627,6 +1610,78 @@ func file_upload_proto_init() {
 			}
 		}
 		file_upload_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UploadRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrafficInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DownloadRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DownloadResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DelegationToken); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GatewayDownloadRequest); i {
 			case 0:
 				return &v.state
@@ -638,19 +1693,68 @@ func file_upload_proto_init() {
 				return nil
 			}
 		}
+		file_upload_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsBackupInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UploadFsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DownloadFsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_upload_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DownloadFsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_upload_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      1,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_upload_proto_goTypes,
 		DependencyIndexes: file_upload_proto_depIdxs,
+		EnumInfos:         file_upload_proto_enumTypes,
 		MessageInfos:      file_upload_proto_msgTypes,
 	}.Build()
 	File_upload_proto = out.File
@@ -18,10 +18,14 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type NodeServiceClient interface {
+	GetNonce(ctx context.Context, in *NonceRequest, opts ...grpc.CallOption) (*Nonce, error)
+	GetTrafficInfo(ctx context.Context, in *TrafficInfo, opts ...grpc.CallOption) (*TrafficInfo, error)
 	UploadFile(ctx context.Context, opts ...grpc.CallOption) (NodeService_UploadFileClient, error)
-	UpdateFs(ctx context.Context, in *FsInfo, opts ...grpc.CallOption) (*Response, error)
+	UpdateFs(ctx context.Context, in *FsInfo, opts ...grpc.CallOption) (*FileSystemStateResponse, error)
 	DownloadFile(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (NodeService_DownloadFileClient, error)
 	GatewayDownloadFile(ctx context.Context, in *GatewayDownloadRequest, opts ...grpc.CallOption) (NodeService_GatewayDownloadFileClient, error)
+	UploadFS(ctx context.Context, opts ...grpc.CallOption) (NodeService_UploadFSClient, error)
+	DownloadFS(ctx context.Context, in *DownloadFsRequest, opts ...grpc.CallOption) (NodeService_DownloadFSClient, error)
 }
 
 type nodeServiceClient struct {
@@ -32,6 +36,24 @@ func NewNodeServiceClient(cc grpc.ClientConnInterface) NodeServiceClient {
 	return &nodeServiceClient{cc}
 }
 
+func (c *nodeServiceClient) GetNonce(ctx context.Context, in *NonceRequest, opts ...grpc.CallOption) (*Nonce, error) {
+	out := new(Nonce)
+	err := c.cc.Invoke(ctx, "/loads.NodeService/GetNonce", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) GetTrafficInfo(ctx context.Context, in *TrafficInfo, opts ...grpc.CallOption) (*TrafficInfo, error) {
+	out := new(TrafficInfo)
+	err := c.cc.Invoke(ctx, "/loads.NodeService/GetTrafficInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *nodeServiceClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (NodeService_UploadFileClient, error) {
 	stream, err := c.cc.NewStream(ctx, &NodeService_ServiceDesc.Streams[0], "/loads.NodeService/UploadFile", opts...)
 	if err != nil {
@@ -66,8 +88,8 @@ func (x *nodeServiceUploadFileClient) CloseAndRecv() (*Response, error) {
 	return m, nil
 }
 
-func (c *nodeServiceClient) UpdateFs(ctx context.Context, in *FsInfo, opts ...grpc.CallOption) (*Response, error) {
-	out := new(Response)
+func (c *nodeServiceClient) UpdateFs(ctx context.Context, in *FsInfo, opts ...grpc.CallOption) (*FileSystemStateResponse, error) {
+	out := new(FileSystemStateResponse)
 	err := c.cc.Invoke(ctx, "/loads.NodeService/UpdateFs", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -139,14 +161,84 @@ func (x *nodeServiceGatewayDownloadFileClient) Recv() (*DownloadResponse, error)
 	return m, nil
 }
 
+func (c *nodeServiceClient) UploadFS(ctx context.Context, opts ...grpc.CallOption) (NodeService_UploadFSClient, error) {
+	stream, err := c.cc.NewStream(ctx, &NodeService_ServiceDesc.Streams[3], "/loads.NodeService/UploadFS", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeServiceUploadFSClient{stream}
+	return x, nil
+}
+
+type NodeService_UploadFSClient interface {
+	Send(*UploadFsRequest) error
+	CloseAndRecv() (*Response, error)
+	grpc.ClientStream
+}
+
+type nodeServiceUploadFSClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeServiceUploadFSClient) Send(m *UploadFsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *nodeServiceUploadFSClient) CloseAndRecv() (*Response, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Response)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *nodeServiceClient) DownloadFS(ctx context.Context, in *DownloadFsRequest, opts ...grpc.CallOption) (NodeService_DownloadFSClient, error) {
+	stream, err := c.cc.NewStream(ctx, &NodeService_ServiceDesc.Streams[4], "/loads.NodeService/DownloadFS", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeServiceDownloadFSClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NodeService_DownloadFSClient interface {
+	Recv() (*DownloadFsResponse, error)
+	grpc.ClientStream
+}
+
+type nodeServiceDownloadFSClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeServiceDownloadFSClient) Recv() (*DownloadFsResponse, error) {
+	m := new(DownloadFsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // NodeServiceServer is the server API for NodeService service.
 // All implementations must embed UnimplementedNodeServiceServer
 // for forward compatibility
 type NodeServiceServer interface {
+	GetNonce(context.Context, *NonceRequest) (*Nonce, error)
+	GetTrafficInfo(context.Context, *TrafficInfo) (*TrafficInfo, error)
 	UploadFile(NodeService_UploadFileServer) error
-	UpdateFs(context.Context, *FsInfo) (*Response, error)
+	UpdateFs(context.Context, *FsInfo) (*FileSystemStateResponse, error)
 	DownloadFile(*DownloadRequest, NodeService_DownloadFileServer) error
 	GatewayDownloadFile(*GatewayDownloadRequest, NodeService_GatewayDownloadFileServer) error
+	UploadFS(NodeService_UploadFSServer) error
+	DownloadFS(*DownloadFsRequest, NodeService_DownloadFSServer) error
 	mustEmbedUnimplementedNodeServiceServer()
 }
 
@@ -154,10 +246,16 @@ type NodeServiceServer interface {
 type UnimplementedNodeServiceServer struct {
 }
 
+func (UnimplementedNodeServiceServer) GetNonce(context.Context, *NonceRequest) (*Nonce, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNonce not implemented")
+}
+func (UnimplementedNodeServiceServer) GetTrafficInfo(context.Context, *TrafficInfo) (*TrafficInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrafficInfo not implemented")
+}
 func (UnimplementedNodeServiceServer) UploadFile(NodeService_UploadFileServer) error {
 	return status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
 }
-func (UnimplementedNodeServiceServer) UpdateFs(context.Context, *FsInfo) (*Response, error) {
+func (UnimplementedNodeServiceServer) UpdateFs(context.Context, *FsInfo) (*FileSystemStateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateFs not implemented")
 }
 func (UnimplementedNodeServiceServer) DownloadFile(*DownloadRequest, NodeService_DownloadFileServer) error {
@@ -166,6 +264,12 @@ func (UnimplementedNodeServiceServer) DownloadFile(*DownloadRequest, NodeService
 func (UnimplementedNodeServiceServer) GatewayDownloadFile(*GatewayDownloadRequest, NodeService_GatewayDownloadFileServer) error {
 	return status.Errorf(codes.Unimplemented, "method GatewayDownloadFile not implemented")
 }
+func (UnimplementedNodeServiceServer) UploadFS(NodeService_UploadFSServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadFS not implemented")
+}
+func (UnimplementedNodeServiceServer) DownloadFS(*DownloadFsRequest, NodeService_DownloadFSServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadFS not implemented")
+}
 func (UnimplementedNodeServiceServer) mustEmbedUnimplementedNodeServiceServer() {}
 
 // UnsafeNodeServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -179,6 +283,42 @@ func RegisterNodeServiceServer(s grpc.ServiceRegistrar, srv NodeServiceServer) {
 	s.RegisterService(&NodeService_ServiceDesc, srv)
 }
 
+func _NodeService_GetNonce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NonceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetNonce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/loads.NodeService/GetNonce",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetNonce(ctx, req.(*NonceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_GetTrafficInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrafficInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).GetTrafficInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/loads.NodeService/GetTrafficInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).GetTrafficInfo(ctx, req.(*TrafficInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _NodeService_UploadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(NodeServiceServer).UploadFile(&nodeServiceUploadFileServer{stream})
 }
@@ -265,6 +405,53 @@ func (x *nodeServiceGatewayDownloadFileServer) Send(m *DownloadResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _NodeService_UploadFS_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NodeServiceServer).UploadFS(&nodeServiceUploadFSServer{stream})
+}
+
+type NodeService_UploadFSServer interface {
+	SendAndClose(*Response) error
+	Recv() (*UploadFsRequest, error)
+	grpc.ServerStream
+}
+
+type nodeServiceUploadFSServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeServiceUploadFSServer) SendAndClose(m *Response) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *nodeServiceUploadFSServer) Recv() (*UploadFsRequest, error) {
+	m := new(UploadFsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _NodeService_DownloadFS_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadFsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServiceServer).DownloadFS(m, &nodeServiceDownloadFSServer{stream})
+}
+
+type NodeService_DownloadFSServer interface {
+	Send(*DownloadFsResponse) error
+	grpc.ServerStream
+}
+
+type nodeServiceDownloadFSServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeServiceDownloadFSServer) Send(m *DownloadFsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // NodeService_ServiceDesc is the grpc.ServiceDesc for NodeService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -272,6 +459,14 @@ var NodeService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "loads.NodeService",
 	HandlerType: (*NodeServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNonce",
+			Handler:    _NodeService_GetNonce_Handler,
+		},
+		{
+			MethodName: "GetTrafficInfo",
+			Handler:    _NodeService_GetTrafficInfo_Handler,
+		},
 		{
 			MethodName: "UpdateFs",
 			Handler:    _NodeService_UpdateFs_Handler,
@@ -293,6 +488,16 @@ var NodeService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _NodeService_GatewayDownloadFile_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "UploadFS",
+			Handler:       _NodeService_UploadFS_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadFS",
+			Handler:       _NodeService_DownloadFS_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "upload.proto",
 }
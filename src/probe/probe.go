@@ -0,0 +1,390 @@
+package probe
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/DeNetPRO/src/account"
+	"github.com/DeNetPRO/src/auth"
+	blckChain "github.com/DeNetPRO/src/blockchain_provider"
+	"github.com/DeNetPRO/src/health"
+	ipWatcher "github.com/DeNetPRO/src/ip_watcher"
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/pb"
+	"github.com/DeNetPRO/src/reload"
+	tlsCert "github.com/DeNetPRO/src/tls_cert"
+	"github.com/DeNetPRO/src/upnp"
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Problem kinds.
+const (
+	NotRegistered = "not registered"
+	PortMismatch  = "port mismatch"
+	IPMismatch    = "ip mismatch"
+	PortClosed    = "port closed"
+	TLSMismatch   = "tls mismatch"
+	OtherNode     = "other node"
+	RPCFailed     = "rpc failed"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	rpcTimeout  = 20 * time.Second
+
+	// rpc server and port mapping are given that long to start before startup probe
+	startupDelay = 30 * time.Second
+)
+
+// Problem Details explains what is wrong and what can be done about it.
+type Problem struct {
+	Kind    string `json:"kind"`
+	Details string `json:"details"`
+}
+
+// Report Address is ip:port from node record in smart contract, Reachable is set when
+// signed request sent to that address is answered by this node.
+type Report struct {
+	Address   string    `json:"address"`
+	PublicIP  string    `json:"publicIP,omitempty"`
+	TLS       bool      `json:"tls"`
+	Reachable bool      `json:"reachable"`
+	Problems  []Problem `json:"problems"`
+}
+
+// Chain reads node info from smart contract.
+type Chain interface {
+	NodeInfo(ctx context.Context) (string, string, error)
+}
+
+// Prober checks that storage providers can reach the node by the address written in smart contract.
+// Nonces are checked with auth, so Check works only in the process that runs rpc server.
+type Prober struct {
+	NodeAddr common.Address
+	Chain    Chain
+	Sources  []ipWatcher.Source
+	Sign     tlsCert.Signer
+}
+
+// chain reads node info of the logged in account.
+type chain struct {
+	nodeAddr common.Address
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (c chain) NodeInfo(ctx context.Context) (string, string, error) {
+	return blckChain.NodeInfo(ctx, c.nodeAddr)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// New makes prober of the logged in account, public ip is detected with ip watcher sources.
+func New(nodeAddr common.Address, conf nodeTypes.IPWatcherConfig) *Prober {
+	return &Prober{
+		NodeAddr: nodeAddr,
+		Chain:    chain{nodeAddr: nodeAddr},
+		Sources:  ipWatcher.Sources(conf),
+		Sign:     account.Sign,
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Check reads node record, dials its address and sends signed request there. Error is returned
+// only if node record can't be read, reachability problems are listed in report.
+func (p *Prober) Check(ctx context.Context, conf nodeTypes.Config) (Report, error) {
+	const location = "probe.Prober.Check->"
+
+	report := Report{Problems: []Problem{}}
+
+	ip, port, err := p.Chain.NodeInfo(ctx)
+	if err != nil {
+		return report, logger.MarkLocation(location, err)
+	}
+
+	if ip == "0.0.0.0" || port == "0" {
+		report.add(NotRegistered, "node record has no address, node must be registered in the network first")
+		return report, nil
+	}
+
+	report.Address = net.JoinHostPort(ip, port)
+
+	localPort := strings.TrimPrefix(conf.HTTPPort, ":")
+	if localPort != port {
+		report.add(PortMismatch, fmt.Sprintf("node record has port %s, but node listens on port %s", port, localPort))
+	}
+
+	if len(p.Sources) != 0 {
+		watcher := &ipWatcher.Watcher{Sources: p.Sources}
+
+		publicIP, err := watcher.Detect(ctx)
+		if err == nil {
+			report.PublicIP = publicIP
+
+			if publicIP != ip {
+				report.add(IPMismatch, fmt.Sprintf("node record has ip %s, but public ip is %s, ip watcher updates node record unless it's disabled", ip, publicIP))
+			}
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", report.Address)
+	if err != nil {
+		report.add(PortClosed, portClosedDetails(report.Address, err, conf.NAT))
+		return report, nil
+	}
+
+	report.TLS = servesTLS(conn)
+	conn.Close()
+
+	if report.TLS {
+		node, err := p.certificateNode(ctx, report.Address)
+		if err == nil && node != p.NodeAddr {
+			report.add(OtherNode, fmt.Sprintf("%s presents certificate of node %s, address in node record points to another node", report.Address, node))
+			return report, nil
+		}
+	}
+
+	if report.TLS && !conf.TLS.Enabled {
+		report.add(TLSMismatch, fmt.Sprintf("tls is disabled in config, but %s serves tls, address in node record may point to another service", report.Address))
+	}
+
+	if !report.TLS && conf.TLS.Enabled {
+		report.add(TLSMismatch, fmt.Sprintf("tls is enabled in config, but %s accepts plain connections, address in node record may point to another service", report.Address))
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	err = p.roundTrip(rpcCtx, report.Address, conf.Network, report.TLS)
+	if err != nil {
+		if errors.Is(err, errOtherNode) {
+			report.add(OtherNode, fmt.Sprintf("%s is served by another node, nonce wasn't issued by this node", report.Address))
+			return report, nil
+		}
+
+		report.add(RPCFailed, fmt.Sprintf("signed request to %s failed: %s", report.Address, rpcError(err)))
+		return report, nil
+	}
+
+	report.Reachable = true
+
+	return report, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+var errOtherNode = errors.New("nonce is issued by another node")
+
+// roundTrip gets nonce and sends signed GetTrafficInfo request for node's own address, it has no side effects.
+func (p *Prober) roundTrip(ctx context.Context, address, network string, useTLS bool) error {
+	const location = "probe.Prober.roundTrip->"
+
+	creds := insecure.NewCredentials()
+	if useTLS {
+		// certificate binding is checked separately, it's not set when certificate is signed by ca
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	conn, err := grpc.DialContext(ctx, address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	defer conn.Close()
+
+	client := pb.NewNodeServiceClient(conn)
+
+	nonce, err := client.GetNonce(ctx, &pb.NonceRequest{Signer: p.NodeAddr.Bytes()})
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if !auth.Issued(nonce.Nonce, p.NodeAddr) {
+		return logger.MarkLocation(location, errOtherNode)
+	}
+
+	spAddress := p.NodeAddr.String()
+	timestamp := time.Now().Unix()
+
+	digest := auth.Digest("GetTrafficInfo", network, sha256.Sum256([]byte(spAddress)), nonce.Nonce, timestamp)
+
+	signature, err := p.Sign(digest[:])
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	_, err = client.GetTrafficInfo(ctx, &pb.TrafficInfo{
+		Network:   network,
+		SpAddress: spAddress,
+		Auth: &pb.Signature{
+			Signer:      p.NodeAddr.Bytes(),
+			Nonce:       nonce.Nonce,
+			SignedBytes: signature,
+			Timestamp:   timestamp,
+		},
+	})
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// certificateNode returns address of the node that certificate served on address is bound to.
+func (p *Prober) certificateNode(ctx context.Context, address string) (common.Address, error) {
+	const location = "probe.Prober.certificateNode->"
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: dialTimeout},
+		Config:    &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"h2"}},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return common.Address{}, logger.MarkLocation(location, err)
+	}
+
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return common.Address{}, logger.MarkLocation(location, errors.New("no certificate is presented"))
+	}
+
+	node, err := tlsCert.NodeAddress(certs[0])
+	if err != nil {
+		return common.Address{}, logger.MarkLocation(location, err)
+	}
+
+	return node, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// servesTLS sends tls handshake, plain grpc server closes connection or responds with non-tls record then.
+func servesTLS(conn net.Conn) bool {
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"h2"}})
+
+	return tlsConn.Handshake() == nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func portClosedDetails(address string, err error, nat nodeTypes.NATConfig) string {
+	var details string
+
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		details = fmt.Sprintf("connection to %s is refused, node doesn't listen on that port or router forwards it to another host", address)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		details = fmt.Sprintf("connection to %s timed out, port is blocked by firewall or isn't forwarded on router", address)
+	default:
+		details = fmt.Sprintf("connection to %s failed: %s", address, err)
+	}
+
+	if nat.Disabled {
+		details += ", port mapping is disabled, so port must be forwarded manually"
+	} else if mapping := upnp.Current(); !mapping.Mapped {
+		details += ", port mapping is not active"
+
+		if mapping.Error != "" {
+			details += ": " + mapping.Error
+		}
+	}
+
+	// connection from the local network to own public ip needs hairpin nat
+	return details + ". Some routers don't let local hosts connect to their public address, check from outside of the network to be sure"
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func rpcError(err error) string {
+	if s, ok := status.FromError(errors.Unwrap(err)); ok {
+		return s.Message()
+	}
+
+	return err.Error()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (r *Report) add(kind, details string) {
+	r.Problems = append(r.Problems, Problem{Kind: kind, Details: details})
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Start checks reachability once rpc server and port mapping had time to start, then waits until ctx is done.
+func Start(ctx context.Context, nodeAddr common.Address) error {
+	const location = "probe.Start->"
+
+	health.Register(health.Probe, 0)
+
+	timer := time.NewTimer(startupDelay)
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+		return nil
+	}
+
+	nodeConfig := reload.Current()
+
+	report, err := New(nodeAddr, nodeConfig.IPWatcher).Check(ctx, nodeConfig)
+	if err != nil && ctx.Err() == nil {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+
+	if err == nil {
+		Print(report)
+
+		for _, problem := range report.Problems {
+			logger.Warn("node reachability problem", logger.Fields{"kind": problem.Kind, "details": problem.Details})
+		}
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Print shows report to user.
+func Print(report Report) {
+	if report.Reachable {
+		fmt.Println("node is reachable at", report.Address)
+	} else if report.Address != "" {
+		fmt.Println("node is not reachable at", report.Address)
+	} else {
+		fmt.Println("node is not reachable")
+	}
+
+	for _, problem := range report.Problems {
+		fmt.Printf("\t%s: %s\n", problem.Kind, problem.Details)
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,185 @@
+package probe_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"net"
+	"testing"
+
+	"github.com/DeNetPRO/src/auth"
+	ipWatcher "github.com/DeNetPRO/src/ip_watcher"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/pb"
+	"github.com/DeNetPRO/src/probe"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const network = "kovan"
+
+type fakeChain struct {
+	ip   string
+	port string
+}
+
+func (c fakeChain) NodeInfo(ctx context.Context) (string, string, error) {
+	return c.ip, c.port, nil
+}
+
+type fakeSource struct {
+	ip net.IP
+}
+
+func (s fakeSource) Name() string {
+	return "fake"
+}
+
+func (s fakeSource) PublicIP(ctx context.Context) (net.IP, error) {
+	return s.ip, nil
+}
+
+// fakeNode checks signed requests like rpc server does, otherNode issues its own nonces.
+type fakeNode struct {
+	pb.UnimplementedNodeServiceServer
+	otherNode bool
+}
+
+func (n *fakeNode) GetNonce(ctx context.Context, req *pb.NonceRequest) (*pb.Nonce, error) {
+	if n.otherNode {
+		nonce := make([]byte, 32)
+		rand.Read(nonce)
+
+		return &pb.Nonce{Nonce: nonce}, nil
+	}
+
+	nonce, expiresAt, err := auth.NewNonce(req.Signer, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Nonce{Nonce: nonce, ExpiresAt: expiresAt}, nil
+}
+
+func (n *fakeNode) GetTrafficInfo(ctx context.Context, req *pb.TrafficInfo) (*pb.TrafficInfo, error) {
+	_, err := auth.Verify(req.Auth, "GetTrafficInfo", req.Network, sha256.Sum256([]byte(req.SpAddress)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.TrafficInfo{Network: req.Network, SpAddress: req.SpAddress}, nil
+}
+
+func startNode(t *testing.T, node *fakeNode) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	pb.RegisterNodeServiceServer(server, node)
+
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	_, port, err := net.SplitHostPort(lis.Addr().String())
+	require.NoError(t, err)
+
+	return port
+}
+
+func newProber(t *testing.T, port string) *probe.Prober {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	return &probe.Prober{
+		NodeAddr: crypto.PubkeyToAddress(key.PublicKey),
+		Chain:    fakeChain{ip: "127.0.0.1", port: port},
+		Sign:     signer(key),
+	}
+}
+
+func signer(key *ecdsa.PrivateKey) func(hash []byte) ([]byte, error) {
+	return func(hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, key)
+	}
+}
+
+func nodeConfig(port string) nodeTypes.Config {
+	return nodeTypes.Config{
+		HTTPPort: ":" + port,
+		Network:  network,
+		NAT:      nodeTypes.NATConfig{Disabled: true},
+	}
+}
+
+func kinds(report probe.Report) []string {
+	list := []string{}
+
+	for _, problem := range report.Problems {
+		list = append(list, problem.Kind)
+	}
+
+	return list
+}
+
+func TestReachable(t *testing.T) {
+	port := startNode(t, &fakeNode{})
+
+	report, err := newProber(t, port).Check(context.Background(), nodeConfig(port))
+	require.NoError(t, err)
+	require.True(t, report.Reachable)
+	require.False(t, report.TLS)
+	require.Equal(t, "127.0.0.1:"+port, report.Address)
+	require.Empty(t, report.Problems)
+}
+
+func TestNotRegistered(t *testing.T) {
+	prober := newProber(t, "0")
+	prober.Chain = fakeChain{ip: "0.0.0.0", port: "0"}
+
+	report, err := prober.Check(context.Background(), nodeConfig("55050"))
+	require.NoError(t, err)
+	require.False(t, report.Reachable)
+	require.Equal(t, []string{probe.NotRegistered}, kinds(report))
+}
+
+func TestPortClosed(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	_, port, err := net.SplitHostPort(lis.Addr().String())
+	require.NoError(t, err)
+
+	lis.Close()
+
+	report, err := newProber(t, port).Check(context.Background(), nodeConfig(port))
+	require.NoError(t, err)
+	require.False(t, report.Reachable)
+	require.Equal(t, []string{probe.PortClosed}, kinds(report))
+	require.Contains(t, report.Problems[0].Details, "refused")
+}
+
+func TestMismatch(t *testing.T) {
+	port := startNode(t, &fakeNode{})
+
+	prober := newProber(t, port)
+	prober.Sources = []ipWatcher.Source{fakeSource{ip: net.IPv4(203, 0, 113, 7)}}
+
+	conf := nodeConfig("55050")
+	conf.TLS.Enabled = true
+
+	report, err := prober.Check(context.Background(), conf)
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.7", report.PublicIP)
+	require.Equal(t, []string{probe.PortMismatch, probe.IPMismatch, probe.TLSMismatch}, kinds(report))
+}
+
+func TestOtherNode(t *testing.T) {
+	port := startNode(t, &fakeNode{otherNode: true})
+
+	report, err := newProber(t, port).Check(context.Background(), nodeConfig(port))
+	require.NoError(t, err)
+	require.False(t, report.Reachable)
+	require.Equal(t, []string{probe.OtherNode}, kinds(report))
+}
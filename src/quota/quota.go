@@ -0,0 +1,264 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+)
+
+// usage is recounted from disk after refreshInterval, so space released by cleaner is taken into account.
+const refreshInterval = time.Minute * 10
+
+var mutex sync.Mutex
+
+type networkUsage struct {
+	total     int64
+	sps       map[string]int64
+	countedAt time.Time
+}
+
+// usage is counted from stored parts and kept up to date by Add and Remove between recounts.
+// Space reserved for uploads in progress is kept apart, so recount doesn't drop it.
+var (
+	usage        = map[string]*networkUsage{}
+	reservations = map[string]*networkUsage{}
+)
+
+// Reserve checks storage provider's and network's quotas and reserves file size for upload, see Release.
+func Reserve(nodeConfig nodeTypes.Config, network, spAddress string, fileSize int64) error {
+	const location = "quota.Reserve->"
+
+	storageLimit := int64(nodeConfig.StorageLimit) * 1024 * 1024 * 1024
+
+	spLimit := limit(spQuota(nodeConfig.Quotas, spAddress), storageLimit)
+	networkLimit := limit(nodeConfig.Quotas.Networks[network], storageLimit)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	reserved := reservationsOf(network)
+
+	// usage isn't counted from disk when there are no limits
+	if spLimit == 0 && networkLimit == 0 {
+		reserved.add(spAddress, fileSize)
+		return nil
+	}
+
+	netUsage, err := countUsage(network)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if spLimit != 0 && netUsage.sps[spAddress]+reserved.sps[spAddress]+fileSize > spLimit {
+		return logger.MarkLocation(location, fmt.Errorf("%s: %w", spAddress, errs.List().Quota))
+	}
+
+	if networkLimit != 0 && netUsage.total+reserved.total+fileSize > networkLimit {
+		return logger.MarkLocation(location, fmt.Errorf("%s: %w", network, errs.List().Quota))
+	}
+
+	reserved.add(spAddress, fileSize)
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Release ends reservation made by Reserve when upload is finished or failed. Stored bytes are counted with Add.
+func Release(network, spAddress string, size int64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	reservationsOf(network).add(spAddress, -size)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Add counts bytes that were stored to storage provider's directory since usage was counted.
+// Bytes that are already counted by recount are counted twice until the next one, quotas are stricter meanwhile.
+func Add(network, spAddress string, size int64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	netUsage, counted := usage[network]
+	if counted {
+		netUsage.add(spAddress, size)
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Remove subtracts bytes of parts that were removed from storage provider's directory.
+func Remove(network, spAddress string, size int64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	netUsage, counted := usage[network]
+	if counted {
+		netUsage.add(spAddress, -size)
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Usage returns count of bytes stored and reserved by storage provider and total count of bytes stored and reserved in network.
+func Usage(network, spAddress string) (int64, int64, error) {
+	const location = "quota.Usage->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	netUsage, err := countUsage(network)
+	if err != nil {
+		return 0, 0, logger.MarkLocation(location, err)
+	}
+
+	reserved := reservationsOf(network)
+
+	return netUsage.sps[spAddress] + reserved.sps[spAddress], netUsage.total + reserved.total, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// StorageProviders returns count of bytes stored and reserved by each storage provider in network.
+func StorageProviders(network string) (map[string]int64, error) {
+	const location = "quota.StorageProviders->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	netUsage, err := countUsage(network)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	sps := make(map[string]int64, len(netUsage.sps))
+	for spAddress, used := range netUsage.sps {
+		sps[spAddress] = used
+	}
+
+	for spAddress, reserved := range reservationsOf(network).sps {
+		sps[spAddress] += reserved
+	}
+
+	return sps, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func spQuota(quotas nodeTypes.QuotaConfig, spAddress string) nodeTypes.Quota {
+	for address, quota := range quotas.StorageProviders {
+		if strings.EqualFold(address, spAddress) {
+			return quota
+		}
+	}
+
+	return quotas.Default
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// limit returns quota in bytes, zero means no limit.
+func limit(quota nodeTypes.Quota, storageLimit int64) int64 {
+	bytesLimit := quota.Bytes
+
+	if bytesLimit < 0 {
+		bytesLimit = 0
+	}
+
+	if quota.Percent > 0 {
+		percentLimit := int64(float64(storageLimit) * quota.Percent / 100)
+
+		if bytesLimit == 0 || percentLimit < bytesLimit {
+			bytesLimit = percentLimit
+		}
+	}
+
+	return bytesLimit
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// countUsage must be called with mutex locked.
+func countUsage(network string) (*networkUsage, error) {
+	const location = "quota.countUsage->"
+
+	netUsage, counted := usage[network]
+
+	if counted && time.Since(netUsage.countedAt) < refreshInterval {
+		return netUsage, nil
+	}
+
+	netUsage = &networkUsage{sps: map[string]int64{}, countedAt: time.Now()}
+
+	pathToNetwork := filepath.Join(paths.List().Storages[0], network)
+
+	spDirs, err := os.ReadDir(pathToNetwork)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	for _, spDir := range spDirs {
+		if !spDir.IsDir() {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(pathToNetwork, spDir.Name()))
+		if err != nil {
+			return nil, logger.MarkLocation(location, err)
+		}
+
+		for _, f := range files {
+			info, err := f.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			netUsage.sps[spDir.Name()] += info.Size()
+			netUsage.total += info.Size()
+		}
+	}
+
+	usage[network] = netUsage
+
+	return netUsage, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// reservationsOf must be called with mutex locked.
+func reservationsOf(network string) *networkUsage {
+	reserved, found := reservations[network]
+	if !found {
+		reserved = &networkUsage{sps: map[string]int64{}}
+		reservations[network] = reserved
+	}
+
+	return reserved
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// add changes storage provider's and network's usage by size, usage never gets negative.
+func (u *networkUsage) add(spAddress string, size int64) {
+	u.sps[spAddress] += size
+	if u.sps[spAddress] <= 0 {
+		delete(u.sps, spAddress)
+	}
+
+	u.total += size
+	if u.total < 0 {
+		u.total = 0
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
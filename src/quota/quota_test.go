@@ -0,0 +1,106 @@
+package quota_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DeNetPRO/src/errs"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/quota"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	network     = "kovan"
+	spAddress   = "0x0000000000000000000000000000000000000001"
+	heavyUserSp = "0x0000000000000000000000000000000000000002"
+)
+
+func TestReserve(t *testing.T) {
+	paths.SetStoragePaths([]string{t.TempDir()})
+
+	pathToSpFiles := filepath.Join(paths.List().Storages[0], network, spAddress)
+
+	err := os.MkdirAll(pathToSpFiles, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(pathToSpFiles, "part"), make([]byte, 600), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeConfig := nodeTypes.Config{
+		StorageLimit: 1,
+		Quotas: nodeTypes.QuotaConfig{
+			Default:          nodeTypes.Quota{Bytes: 1000},
+			StorageProviders: map[string]nodeTypes.Quota{heavyUserSp: {Bytes: 5000}},
+			Networks:         map[string]nodeTypes.Quota{network: {Bytes: 3000}},
+		},
+	}
+
+	err = quota.Reserve(nodeConfig, network, spAddress, 400)
+	require.NoError(t, err)
+
+	err = quota.Reserve(nodeConfig, network, spAddress, 1)
+	require.ErrorIs(t, err, errs.List().Quota)
+
+	err = quota.Reserve(nodeConfig, network, heavyUserSp, 2000)
+	require.NoError(t, err)
+
+	err = quota.Reserve(nodeConfig, network, heavyUserSp, 1)
+	require.ErrorIs(t, err, errs.List().Quota)
+
+	spUsage, networkUsage, err := quota.Usage(network, heavyUserSp)
+	require.NoError(t, err)
+	require.Equal(t, int64(2000), spUsage)
+	require.Equal(t, int64(3000), networkUsage)
+
+	// upload of spAddress stored nothing
+	quota.Release(network, spAddress, 400)
+
+	err = quota.Reserve(nodeConfig, network, spAddress, 400)
+	require.NoError(t, err)
+
+	nodeConfig.Quotas = nodeTypes.QuotaConfig{Default: nodeTypes.Quota{Percent: 0.0001}}
+
+	err = quota.Reserve(nodeConfig, "mumbai", spAddress, 1000)
+	require.NoError(t, err)
+
+	err = quota.Reserve(nodeConfig, "mumbai", spAddress, 100)
+	require.ErrorIs(t, err, errs.List().Quota)
+}
+
+func TestReleaseAfterUpload(t *testing.T) {
+	const network = "polygon"
+
+	paths.SetStoragePaths([]string{t.TempDir()})
+
+	nodeConfig := nodeTypes.Config{
+		StorageLimit: 1,
+		Quotas:       nodeTypes.QuotaConfig{Default: nodeTypes.Quota{Bytes: 1000}},
+	}
+
+	err := quota.Reserve(nodeConfig, network, spAddress, 1000)
+	require.NoError(t, err)
+
+	// upload stored only a part of declared size
+	quota.Release(network, spAddress, 1000)
+	quota.Add(network, spAddress, 600)
+
+	spUsage, _, err := quota.Usage(network, spAddress)
+	require.NoError(t, err)
+	require.Equal(t, int64(600), spUsage)
+
+	err = quota.Reserve(nodeConfig, network, spAddress, 401)
+	require.ErrorIs(t, err, errs.List().Quota)
+
+	// parts are removed by cleaner
+	quota.Remove(network, spAddress, 600)
+
+	err = quota.Reserve(nodeConfig, network, spAddress, 1000)
+	require.NoError(t, err)
+}
@@ -0,0 +1,32 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	ratelimit "github.com/DeNetPRO/src/rate_limit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllow(t *testing.T) {
+	limiter := ratelimit.New(10, 3)
+
+	for i := 0; i < 3; i++ {
+		require.True(t, limiter.Allow("127.0.0.1"))
+	}
+
+	require.False(t, limiter.Allow("127.0.0.1"))
+	require.True(t, limiter.Allow("127.0.0.2"))
+
+	time.Sleep(150 * time.Millisecond)
+
+	require.True(t, limiter.Allow("127.0.0.1"))
+}
+
+func TestDisabled(t *testing.T) {
+	limiter := ratelimit.New(0, 0)
+
+	for i := 0; i < 100; i++ {
+		require.True(t, limiter.Allow("127.0.0.1"))
+	}
+}
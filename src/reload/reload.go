@@ -0,0 +1,333 @@
+package reload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DeNetPRO/src/bandwidth"
+	"github.com/DeNetPRO/src/cleaner"
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/health"
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/supervisor"
+	"github.com/DeNetPRO/src/telemetry"
+	"github.com/DeNetPRO/src/traffic"
+)
+
+// config file is checked for changes that often
+const checkInterval = 5 * time.Second
+
+var (
+	mutex        sync.Mutex
+	current      nodeTypes.Config
+	modTime      time.Time
+	lastRejected string
+)
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Init sets config that node is started with.
+func Init(nodeConfig nodeTypes.Config) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	current = nodeConfig
+	lastRejected = ""
+
+	stat, err := os.Stat(paths.List().ConfigFile)
+	if err == nil {
+		modTime = stat.ModTime()
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Current returns config with the changes that were applied without restart.
+func Current() nodeTypes.Config {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return current
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Watch reloads config when config file is modified until ctx is done.
+func Watch(ctx context.Context) error {
+	const location = "reload.Watch->"
+
+	health.Register(health.Config, 0)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+
+		stat, err := os.Stat(paths.List().ConfigFile)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			continue
+		}
+
+		mutex.Lock()
+		modified := !stat.ModTime().Equal(modTime)
+		mutex.Unlock()
+
+		if !modified {
+			continue
+		}
+
+		err = Reload()
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Reload reads config file and applies changes that don't need restart. Changes that need restart
+// are reported and not applied until node is restarted. Invalid config is not applied at all.
+func Reload() error {
+	const location = "reload.Reload->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	stat, err := os.Stat(paths.List().ConfigFile)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	modTime = stat.ModTime()
+
+	fileBytes, err := os.ReadFile(paths.List().ConfigFile)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	var newConfig nodeTypes.Config
+
+	err = json.Unmarshal(fileBytes, &newConfig)
+	if err != nil {
+		fmt.Println("config file is not valid, changes are not applied:", err)
+		return logger.MarkLocation(location, err)
+	}
+
+	err = validate(newConfig)
+	if err != nil {
+		fmt.Println("config is not valid, changes are not applied:", err)
+		return logger.MarkLocation(location, err)
+	}
+
+	applied, rejected := keepRestartFields(current, newConfig)
+
+	changed := apply(current, applied)
+
+	current = applied
+
+	if len(changed) != 0 {
+		fmt.Println("config is reloaded, applied changes of:", strings.Join(changed, ", "))
+		logger.Info("config is reloaded", logger.Fields{"changed": changed})
+	}
+
+	rejectedFields := strings.Join(rejected, ", ")
+
+	if rejectedFields != lastRejected {
+		lastRejected = rejectedFields
+
+		if len(rejected) != 0 {
+			fmt.Println("changes of", rejectedFields, "are not applied, please restart node to apply them")
+			logger.Warn("config changes need restart", logger.Fields{"fields": rejected})
+		}
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func validate(nodeConfig nodeTypes.Config) error {
+	if nodeConfig.StorageLimit <= 0 {
+		return errors.New("storage limit should be positive number")
+	}
+
+	rpcURL, err := url.Parse(nodeConfig.RPC[nodeConfig.Network])
+	if err != nil || rpcURL.Host == "" {
+		return fmt.Errorf("rpc url for %s network is not valid", nodeConfig.Network)
+	}
+
+	_, err = logger.ParseLevel(nodeConfig.Logging.Level)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// keepRestartFields returns new config where fields that can't be changed without restart have old values,
+// names of these fields are returned too.
+func keepRestartFields(old, new nodeTypes.Config) (nodeTypes.Config, []string) {
+	rejected := []string{}
+
+	if old.Address != new.Address {
+		rejected = append(rejected, "nodeAddress")
+		new.Address = old.Address
+	}
+
+	if old.HTTPPort != new.HTTPPort {
+		rejected = append(rejected, "portHTTP")
+		new.HTTPPort = old.HTTPPort
+	}
+
+	if old.Network != new.Network {
+		rejected = append(rejected, "network")
+		new.Network = old.Network
+	}
+
+	if !reflect.DeepEqual(old.StoragePaths, new.StoragePaths) {
+		rejected = append(rejected, "storagePaths")
+		new.StoragePaths = old.StoragePaths
+	}
+
+	if old.TLS != new.TLS {
+		rejected = append(rejected, "tls")
+		new.TLS = old.TLS
+	}
+
+	if !reflect.DeepEqual(old.Limits, new.Limits) {
+		rejected = append(rejected, "limits")
+		new.Limits = old.Limits
+	}
+
+	if old.Metrics != new.Metrics {
+		rejected = append(rejected, "metrics")
+		new.Metrics = old.Metrics
+	}
+
+	if old.Health != new.Health {
+		rejected = append(rejected, "health")
+		new.Health = old.Health
+	}
+
+	// port mapping worker is started only if it's enabled and keeps its gateway settings
+	if old.NAT != new.NAT {
+		rejected = append(rejected, "nat")
+		new.NAT = old.NAT
+	}
+
+	if old.Admin != new.Admin {
+		rejected = append(rejected, "admin")
+		new.Admin = old.Admin
+	}
+
+	// telemetry queue is made once
+	if old.Telemetry.QueueSize != new.Telemetry.QueueSize || old.Telemetry.BatchSize != new.Telemetry.BatchSize ||
+		old.Telemetry.FlushInterval != new.Telemetry.FlushInterval {
+
+		rejected = append(rejected, "telemetry queue")
+		new.Telemetry.QueueSize = old.Telemetry.QueueSize
+		new.Telemetry.BatchSize = old.Telemetry.BatchSize
+		new.Telemetry.FlushInterval = old.Telemetry.FlushInterval
+	}
+
+	return new, rejected
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// apply applies changed settings and returns names of changed fields. Storage limit, quotas and gateways
+// are read from config file on each request and ip watcher settings are read on each check, so they are only reported.
+func apply(old, new nodeTypes.Config) []string {
+	const location = "reload.apply->"
+
+	changed := []string{}
+
+	if old.StorageLimit != new.StorageLimit {
+		changed = append(changed, "storageLimit")
+	}
+
+	// ip address is kept in sync with smart contract by ip watcher
+	if old.IpAddress != new.IpAddress {
+		changed = append(changed, "ipAddress")
+	}
+
+	if !reflect.DeepEqual(old.IPWatcher, new.IPWatcher) {
+		changed = append(changed, "ipWatcher")
+	}
+
+	if !reflect.DeepEqual(old.Quotas, new.Quotas) {
+		changed = append(changed, "quotas")
+	}
+
+	if !reflect.DeepEqual(old.Gateways, new.Gateways) {
+		changed = append(changed, "gateways")
+	}
+
+	if !reflect.DeepEqual(old.RPC, new.RPC) {
+		changed = append(changed, "rpc")
+
+		if old.RPC[old.Network] != new.RPC[new.Network] {
+			config.RPC = new.RPC[new.Network]
+
+			// proofs worker keeps connection to rpc endpoint
+			supervisor.Restart(health.Proofs)
+		}
+	}
+
+	if old.SendBugReports != new.SendBugReports {
+		changed = append(changed, "sendBugReports")
+		telemetry.SetErrorReports(new.SendBugReports)
+	}
+
+	if !reflect.DeepEqual(old.Telemetry, new.Telemetry) {
+		changed = append(changed, "telemetry")
+		telemetry.SetConfig(new.Telemetry)
+	}
+
+	if !reflect.DeepEqual(old.Logging, new.Logging) {
+		changed = append(changed, "logging")
+
+		err := logger.Init(new.Logging, filepath.Join(paths.List().WorkDir, "logs"))
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+		}
+	}
+
+	if !reflect.DeepEqual(old.Cleaner, new.Cleaner) {
+		changed = append(changed, "cleaner")
+		cleaner.SetConfig(new.Cleaner)
+	}
+
+	if !reflect.DeepEqual(old.Traffic, new.Traffic) {
+		changed = append(changed, "traffic")
+		traffic.SetConfig(new.Traffic)
+	}
+
+	if !reflect.DeepEqual(old.Bandwidth, new.Bandwidth) {
+		changed = append(changed, "bandwidth")
+		bandwidth.SetConfig(new.Bandwidth)
+	}
+
+	return changed
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,83 @@
+package reload_test
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/DeNetPRO/src/config"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/reload"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	tstpkg.TestModeOn()
+	defer tstpkg.TestModeOff()
+
+	err := paths.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = config.Create(tstpkg.Data().AccAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	exitVal := m.Run()
+
+	err = os.RemoveAll(paths.List().WorkDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitVal)
+}
+
+func writeConfig(t *testing.T, nodeConfig nodeTypes.Config) {
+	confJSON, err := json.Marshal(nodeConfig)
+	require.NoError(t, err)
+
+	err = os.WriteFile(paths.List().ConfigFile, confJSON, 0700)
+	require.NoError(t, err)
+}
+
+func TestReload(t *testing.T) {
+	nodeConfig, err := config.Read()
+	require.NoError(t, err)
+
+	reload.Init(nodeConfig)
+
+	changed := nodeConfig
+	changed.StorageLimit = 5
+	changed.Cleaner.GracePeriod = 3600
+	changed.HTTPPort = ":55051"
+	changed.NAT.Lifetime = 600
+
+	writeConfig(t, changed)
+
+	err = reload.Reload()
+	require.NoError(t, err)
+
+	current := reload.Current()
+	require.Equal(t, 5, current.StorageLimit)
+	require.Equal(t, int64(3600), current.Cleaner.GracePeriod)
+	require.Equal(t, nodeConfig.HTTPPort, current.HTTPPort)
+	require.Equal(t, nodeConfig.NAT, current.NAT)
+
+	invalid := changed
+	invalid.StorageLimit = 0
+	invalid.Cleaner.GracePeriod = 60
+
+	writeConfig(t, invalid)
+
+	err = reload.Reload()
+	require.Error(t, err)
+	require.Equal(t, current, reload.Current())
+
+	writeConfig(t, nodeConfig)
+}
@@ -0,0 +1,45 @@
+package rpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/DeNetPRO/src/health"
+	"github.com/DeNetPRO/src/pb"
+	grpcHealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const healthCheckInterval = 10 * time.Second
+
+// watchHealth updates grpc health statuses: empty service name reports liveness
+// and node service name reports readiness.
+func watchHealth(ctx context.Context, server *grpcHealth.Server) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		isAlive, _ := health.Alive()
+
+		server.SetServingStatus("", servingStatus(isAlive))
+		server.SetServingStatus(pb.NodeService_ServiceDesc.ServiceName, servingStatus(health.Ready()))
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
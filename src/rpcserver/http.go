@@ -0,0 +1,142 @@
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DeNetPRO/src/bandwidth"
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/health"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/metrics"
+	"github.com/DeNetPRO/src/networks"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/quota"
+)
+
+var collectorOnce sync.Once
+
+// startHTTP serves metrics and health endpoints if they are enabled in config.
+// Endpoints with the same address share listener.
+func startHTTP(nodeConfig nodeTypes.Config) []*http.Server {
+	muxes := map[string]*http.ServeMux{}
+
+	mux := func(address string) *http.ServeMux {
+		if address == "" {
+			address = config.DefaultHTTPAddress
+		}
+
+		m, ok := muxes[address]
+		if !ok {
+			m = http.NewServeMux()
+			muxes[address] = m
+		}
+
+		return m
+	}
+
+	if nodeConfig.Metrics.Enabled {
+		collectorOnce.Do(func() {
+			metrics.OnScrape(collectStorageMetrics)
+			metrics.OnScrape(collectBandwidthMetrics)
+		})
+
+		mux(nodeConfig.Metrics.Address).Handle("/metrics", metrics.Handler())
+	}
+
+	if nodeConfig.Health.Enabled {
+		health.RegisterHandlers(mux(nodeConfig.Health.Address))
+	}
+
+	servers := []*http.Server{}
+
+	for address, m := range muxes {
+		server := &http.Server{
+			Addr:              address,
+			Handler:           m,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go serveHTTP(server)
+
+		servers = append(servers, server)
+	}
+
+	return servers
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func serveHTTP(server *http.Server) {
+	const location = "rpcserver.serveHTTP->"
+
+	fmt.Println("serving metrics and status on", server.Addr)
+
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Log(logger.MarkLocation(location, err))
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func stopHTTP(servers []*http.Server) {
+	const location = "rpcserver.stopHTTP->"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, server := range servers {
+		err := server.Shutdown(ctx)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// collectStorageMetrics sets storage gauges, used space is counted by quota which caches it.
+func collectStorageMetrics() {
+	const location = "rpcserver.collectStorageMetrics->"
+
+	nodeConfig, err := config.Read()
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+		return
+	}
+
+	metrics.StorageLimit.Set(float64(int64(nodeConfig.StorageLimit) * 1024 * 1024 * 1024))
+	metrics.StorageReserved.Set(float64(nodeConfig.UsedStorageSpace))
+
+	for _, network := range networks.List() {
+		_, used, err := quota.Usage(network, "")
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			continue
+		}
+
+		metrics.StorageUsed.Set(float64(used), network)
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// collectBandwidthMetrics sets throughput and bandwidth limits applied now.
+func collectBandwidthMetrics() {
+	stat := bandwidth.CurrentStat()
+
+	metrics.Bandwidth.Set(stat.Upload, "upload")
+	metrics.Bandwidth.Set(stat.Download, "download")
+	metrics.Bandwidth.Set(stat.GatewayDownload, "gateway_download")
+
+	metrics.BandwidthLimit.Set(float64(stat.UploadLimit), "upload")
+	metrics.BandwidthLimit.Set(float64(stat.DownloadLimit), "download")
+	metrics.BandwidthLimit.Set(float64(stat.GatewayDownloadLimit), "gateway_download")
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,333 @@
+package rpcserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/metrics"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	ratelimit "github.com/DeNetPRO/src/rate_limit"
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	keepaliveMinTime  = 10 * time.Second
+	keepaliveTime     = 2 * time.Minute
+	keepaliveTimeout  = 20 * time.Second
+	maxConnectionIdle = 5 * time.Minute
+)
+
+// limiter guards rpc handlers from abusive clients. Requests are limited per remote address
+// and per storage provider, streams are limited by size and closed when client is idle.
+// Storage provider's limit is applied by checkAuth, so only a verified signer spends its tokens.
+type limiter struct {
+	conf      nodeTypes.LimitsConfig
+	ipLimiter *ratelimit.Limiter
+	spLimiter *ratelimit.Limiter
+	requests  chan struct{}
+}
+
+// limitedStream receives messages in its own goroutine, so RecvMsg returns when client is idle
+// and handler finishes before the stream is closed. idleTimer runs only while handler waits for the next message.
+type limitedStream struct {
+	grpc.ServerStream
+	ctx         context.Context
+	limiter     *limiter
+	idleTimer   *time.Timer
+	idleTimeout time.Duration
+	messages    chan receivedMessage
+	recvErr     error
+	received    int64
+}
+
+type receivedMessage struct {
+	msg proto.Message
+	err error
+}
+
+// limiterKey stores request's limiter in context for checkAuth.
+type limiterKey struct{}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func newLimiter(conf nodeTypes.LimitsConfig) *limiter {
+	defaults := config.DefaultLimitsConfig
+
+	if conf.IPRate <= 0 {
+		conf.IPRate, conf.IPBurst = defaults.IPRate, defaults.IPBurst
+	}
+
+	if conf.SpRate <= 0 {
+		conf.SpRate, conf.SpBurst = defaults.SpRate, defaults.SpBurst
+	}
+
+	if conf.MaxMessageSize <= 0 {
+		conf.MaxMessageSize = defaults.MaxMessageSize
+	}
+
+	if conf.MaxStreamSize <= 0 {
+		conf.MaxStreamSize = defaults.MaxStreamSize
+	}
+
+	if conf.MaxConcurrentStreams == 0 {
+		conf.MaxConcurrentStreams = defaults.MaxConcurrentStreams
+	}
+
+	if conf.MaxConcurrentRequests <= 0 {
+		conf.MaxConcurrentRequests = defaults.MaxConcurrentRequests
+	}
+
+	if conf.IdleTimeout <= 0 {
+		conf.IdleTimeout = defaults.IdleTimeout
+	}
+
+	return &limiter{
+		conf:      conf,
+		ipLimiter: ratelimit.New(conf.IPRate, conf.IPBurst),
+		spLimiter: ratelimit.New(conf.SpRate, conf.SpBurst),
+		requests:  make(chan struct{}, conf.MaxConcurrentRequests),
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// serverOptions returns grpc server options with limits and interceptor chain.
+func (l *limiter) serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(l.conf.MaxMessageSize),
+		grpc.MaxConcurrentStreams(l.conf.MaxConcurrentStreams),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             keepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: maxConnectionIdle,
+			Time:              keepaliveTime,
+			Timeout:           keepaliveTimeout,
+		}),
+		grpc.ChainUnaryInterceptor(statusUnary, l.unary),
+		grpc.ChainStreamInterceptor(statusStream, l.stream),
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (l *limiter) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !l.ipLimiter.Allow(remoteAddress(ctx)) {
+		return nil, errs.List().RateLimit
+	}
+
+	select {
+	case l.requests <- struct{}{}:
+		defer func() { <-l.requests }()
+	default:
+		return nil, errs.List().RateLimit
+	}
+
+	return handler(context.WithValue(ctx, limiterKey{}, l), req)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (l *limiter) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !l.ipLimiter.Allow(remoteAddress(ss.Context())) {
+		return errs.List().RateLimit
+	}
+
+	select {
+	case l.requests <- struct{}{}:
+		defer func() { <-l.requests }()
+	default:
+		return errs.List().RateLimit
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(ss.Context(), limiterKey{}, l))
+	defer cancel()
+
+	idleTimeout := time.Duration(l.conf.IdleTimeout) * time.Second
+
+	// timer is started by RecvMsg
+	idleTimer := time.AfterFunc(idleTimeout, cancel)
+	defer idleTimer.Stop()
+
+	stream := &limitedStream{
+		ServerStream: ss,
+		ctx:          ctx,
+		limiter:      l,
+		idleTimer:    idleTimer,
+		idleTimeout:  idleTimeout,
+	}
+
+	err := handler(srv, stream)
+	if err != nil && stream.idle() {
+		return errs.List().IdleStream
+	}
+
+	return err
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Context is canceled when client is idle for too long, so handler stops its work too.
+func (s *limitedStream) Context() context.Context {
+	return s.ctx
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// RecvMsg fails if stream exceeds size limit, stream is closed if client doesn't send next message within idle timeout.
+func (s *limitedStream) RecvMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return s.ServerStream.RecvMsg(m)
+	}
+
+	if s.recvErr != nil {
+		return s.recvErr
+	}
+
+	if s.messages == nil {
+		s.messages = make(chan receivedMessage)
+		go s.receive(msg.ProtoReflect().Type())
+	}
+
+	s.idleTimer.Reset(s.idleTimeout)
+
+	var received receivedMessage
+
+	select {
+	case received = <-s.messages:
+		s.idleTimer.Stop()
+	case <-s.ctx.Done():
+		s.idleTimer.Stop()
+
+		s.recvErr = s.ctx.Err()
+		if s.idle() {
+			s.recvErr = errs.List().IdleStream
+		}
+
+		return s.recvErr
+	}
+
+	if received.err != nil {
+		s.recvErr = received.err
+		return s.recvErr
+	}
+
+	proto.Reset(msg)
+	proto.Merge(msg, received.msg)
+
+	s.received += int64(proto.Size(msg))
+
+	if s.received > s.limiter.conf.MaxStreamSize {
+		return errs.List().StreamSize
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// receive reads stream into its own messages, so handler's message is never written after RecvMsg returned.
+// It may stay blocked in the underlying RecvMsg after handler finished, until grpc closes the stream.
+func (s *limitedStream) receive(msgType protoreflect.MessageType) {
+	for {
+		msg := msgType.New().Interface()
+		err := s.ServerStream.RecvMsg(msg)
+
+		select {
+		case s.messages <- receivedMessage{msg: msg, err: err}:
+		case <-s.ctx.Done():
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// idle reports whether stream was canceled by idle timer rather than by client.
+func (s *limitedStream) idle() bool {
+	return s.ctx.Err() != nil && s.ServerStream.Context().Err() == nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// statusUnary converts handler errors to rpc statuses, see errs.Status.
+func statusUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, rpcError(info.FullMethod, err)
+	}
+
+	return resp, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// statusStream converts handler errors to rpc statuses, see errs.Status.
+func statusStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	if err != nil {
+		return rpcError(info.FullMethod, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func rpcError(method string, err error) error {
+	const location = "rpcserver.rpcError->"
+
+	st, known := errs.Status(err)
+	if !known {
+		logger.Error(logger.MarkLocation(location, err), logger.Fields{"method": method})
+	}
+
+	metrics.RPCErrors.Inc(method, st.Code().String())
+
+	return st.Err()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func remoteAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// allowSigner applies storage provider's rate limit to a verified signer.
+func allowSigner(ctx context.Context, signer common.Address) bool {
+	l, ok := ctx.Value(limiterKey{}).(*limiter)
+	if !ok {
+		return true
+	}
+
+	return l.spLimiter.Allow(signer.Hex())
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
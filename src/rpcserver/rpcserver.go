@@ -5,75 +5,272 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
 	"os"
-	"os/signal"
-	"path/filepath"
 	"sort"
-	"sync"
+	"strings"
+	"time"
 
+	"github.com/DeNetPRO/src/account"
+	"github.com/DeNetPRO/src/auth"
+	"github.com/DeNetPRO/src/bandwidth"
+	"github.com/DeNetPRO/src/cleaner"
 	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/gateway"
 	"github.com/DeNetPRO/src/hash"
+	"github.com/DeNetPRO/src/health"
 	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/metrics"
 	"github.com/DeNetPRO/src/networks"
 	"github.com/DeNetPRO/src/paths"
 	"github.com/DeNetPRO/src/pb"
+	"github.com/DeNetPRO/src/quota"
 	"github.com/DeNetPRO/src/sign"
 	spFiles "github.com/DeNetPRO/src/sp_files"
+	"github.com/DeNetPRO/src/telemetry"
+	tlsCert "github.com/DeNetPRO/src/tls_cert"
+	"github.com/DeNetPRO/src/traffic"
 
 	fsysInfo "github.com/DeNetPRO/src/fsys_info"
 
-	nodeFile "github.com/DeNetPRO/src/node_file"
-
 	nodeTypes "github.com/DeNetPRO/src/node_types"
 
-	tstpkg "github.com/DeNetPRO/src/tst_pkg"
-
+	"github.com/ethereum/go-ethereum/common"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpcHealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	emptyPartName = "0000000000000000000000000000000000000000000000000000000000000000"
+	fsChunkSize   = 1024 * 1024
+
+	// in-flight requests are drained for that long on shutdown, then connections are closed
+	drainTimeout = time.Minute
 )
 
 type rpcServer struct {
 	pb.UnimplementedNodeServiceServer
 }
 
-var mutex sync.Mutex
+var (
+	// gatewayCertRequired is set when client CAs are configured, gateways must present verified certificate then.
+	gatewayCertRequired bool
+)
 
-func Start(port string) error {
+// Start serves rpc requests until ctx is done, then waits for in-flight requests to finish.
+func Start(ctx context.Context, nodeConfig nodeTypes.Config) error {
 
 	const location = "rpcserver.Start ->"
 
+	port := nodeConfig.HTTPPort
+
 	lis, err := net.Listen("tcp", port)
 	if err != nil {
 		return logger.MarkLocation(location, err)
 	}
 
-	s := grpc.NewServer()
+	s, err := NewServer(nodeConfig)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
 
-	pb.RegisterNodeServiceServer(s, &rpcServer{})
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	traffic.Start(runCtx, nodeConfig.Address, nodeConfig.Traffic)
+	bandwidth.SetConfig(nodeConfig.Bandwidth)
+	telemetry.Start(nodeConfig.Address, nodeConfig.Telemetry)
+
+	healthServer := grpcHealth.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	health.Register(health.RPCServer, 0)
+
+	go watchHealth(runCtx, healthServer)
+
+	httpServers := startHTTP(nodeConfig)
+	defer stopHTTP(httpServers)
 
 	fmt.Println("starting rpc server on port", port)
 
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- s.Serve(lis)
+	}()
+
+	select {
+	case err = <-serveErr:
+		return logger.MarkLocation(location, err)
+	case <-ctx.Done():
+	}
+
+	healthServer.Shutdown()
+
+	drained := make(chan struct{})
+
 	go func() {
-		err = s.Serve(lis)
+		s.GracefulStop()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+		fmt.Println("in-flight requests are not finished in time, closing connections")
+		s.Stop()
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// NewServer makes grpc server with node service, request limits and tls set in nodeConfig.
+func NewServer(nodeConfig nodeTypes.Config) (*grpc.Server, error) {
+	const location = "rpcserver.NewServer ->"
+
+	opts := newLimiter(nodeConfig.Limits).serverOptions()
+
+	if nodeConfig.TLS.Enabled {
+		serverTLS, err := tlsCert.ServerConfig(nodeConfig.TLS, account.Sign)
 		if err != nil {
-			log.Fatal(err)
+			return nil, logger.MarkLocation(location, err)
 		}
-	}()
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
+		opts = append(opts, grpc.Creds(credentials.NewTLS(serverTLS)))
 
-	<-stop
+		gatewayCertRequired = nodeConfig.TLS.ClientCAFile != ""
 
-	s.GracefulStop()
+		fmt.Println("tls is enabled")
+	}
+
+	s := grpc.NewServer(opts...)
+
+	pb.RegisterNodeServiceServer(s, &rpcServer{})
+
+	return s, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// GetNonce issues one-time nonce that must be signed along with the next request of the signer.
+func (r *rpcServer) GetNonce(ctx context.Context, req *pb.NonceRequest) (*pb.Nonce, error) {
+
+	const location = "rpcserver.GetNonce ->"
+
+	nonce, expiresAt, err := auth.NewNonce(req.Signer, remoteAddress(ctx))
+	if err != nil {
+		if errors.Is(err, errs.List().Argument) {
+			return nil, errs.List().Argument
+		}
 
-	if tstpkg.Data().TestMode {
-		os.RemoveAll(paths.List().WorkDir)
+		if errors.Is(err, errs.List().RateLimit) {
+			return nil, errs.List().RateLimit
+		}
+
+		logger.Log(logger.MarkLocation(location, err))
+		return nil, errs.List().Internal
+	}
+
+	return &pb.Nonce{Nonce: nonce, ExpiresAt: expiresAt}, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// GetTrafficInfo accepts storage provider's receipt of paid traffic, if it's signed, and returns traffic counted by node.
+func (r *rpcServer) GetTrafficInfo(ctx context.Context, req *pb.TrafficInfo) (*pb.TrafficInfo, error) {
+
+	const location = "rpcserver.GetTrafficInfo ->"
+
+	err := checkAuth(ctx, req.Auth, "GetTrafficInfo", req.Network, req.SpAddress, sha256.Sum256([]byte(req.SpAddress)))
+	if err != nil {
+		return nil, err
+	}
+
+	err = networks.Check(req.Network)
+	if err != nil {
+		return nil, errs.List().Network
+	}
+
+	err = addTrafficReceipt(req, req.Network, req.SpAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := traffic.Info(req.Network, req.SpAddress)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+		return nil, errs.List().Internal
+	}
+
+	return &pb.TrafficInfo{
+		Sign:        record.Receipt,
+		Network:     req.Network,
+		SpAddress:   req.SpAddress,
+		PaidTraffic: record.Paid,
+		Uploaded:    record.Uploaded,
+		Downloaded:  record.Downloaded,
+	}, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// addTrafficReceipt keeps paid traffic receipt passed with request, receipt must belong to the requested storage provider.
+func addTrafficReceipt(info *pb.TrafficInfo, network, spAddress string) error {
+
+	const location = "rpcserver.addTrafficReceipt ->"
+
+	if info == nil || len(info.Sign) == 0 {
+		return nil
+	}
+
+	if info.Network != network || !strings.EqualFold(info.SpAddress, spAddress) {
+		return errs.List().Argument
+	}
+
+	err := traffic.AddReceipt(network, spAddress, info.PaidTraffic, info.Sign)
+	if err != nil {
+		if errors.Is(err, errs.List().Signature) {
+			return errs.List().Signature
+		}
+
+		logger.Log(logger.MarkLocation(location, err))
+		return errs.List().Internal
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// checkAuth verifies that request is signed by the expected account and its nonce wasn't used before.
+// Signer address must be in checksum form, because it names storage provider's directories, quota and traffic records.
+// Storage provider's rate limit is applied only after signature is verified, so forged requests can't spend provider's tokens.
+func checkAuth(ctx context.Context, signature *pb.Signature, method, network, signerAddress string, payloadHash [32]byte) error {
+
+	signer, err := auth.Verify(signature, method, network, payloadHash)
+	if err != nil {
+		if errors.Is(err, errs.List().Nonce) {
+			return errs.List().Nonce
+		}
+
+		return errs.List().Signature
+	}
+
+	if signer.Hex() != signerAddress {
+		return errs.List().Signature
+	}
+
+	if !allowSigner(ctx, signer) {
+		return errs.List().RateLimit
 	}
 
 	return nil
@@ -85,40 +282,77 @@ func (r *rpcServer) UploadFile(stream pb.NodeService_UploadFileServer) error {
 
 	const location = "rpcserver.UploadFile ->"
 
+	start := time.Now()
+
 	req, err := stream.Recv()
 	if err != nil {
 		return err
 	}
 
-	err = sign.Check(req.SpAddress, req.SignedAddress, sha256.Sum256([]byte(req.SpAddress)))
+	fileSizeBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(fileSizeBytes, req.FileSize)
+
+	err = checkAuth(stream.Context(), req.Sign, "UploadFile", req.Network, req.SpAddress, sha256.Sum256(append([]byte(req.SpAddress), fileSizeBytes...)))
 	if err != nil {
 		return err
 	}
 
 	err = networks.Check(req.Network)
 	if err != nil {
-		return errors.New("unsupported network")
+		return errs.List().Network
 	}
 
-	pathToSpFiles := filepath.Join(paths.List().Storages[0], req.Network, req.SpAddress)
+	network, spAddress := req.Network, req.SpAddress
+
+	pathToSpFiles, err := paths.SpFilesDir(network, spAddress)
+	if err != nil {
+		return errs.List().Argument
+	}
 
 	dirStat, err := os.Stat(pathToSpFiles)
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		logger.Log(logger.MarkLocation(location, err))
-		return errors.New("couldn't check SP files")
+		return errs.List().Internal
 	}
 
-	err = checkAndReserveSpace(req.FileSize)
+	err = checkAndReserveSpace(req.Network, req.SpAddress, req.FileSize)
 	if err != nil {
-		logger.Log(logger.MarkLocation(location, err))
-		return errors.New("couldn't reserve space")
+		logger.Error(logger.MarkLocation(location, err), logger.Fields{"network": network, "sp": spAddress})
+
+		for _, knownErr := range []error{errs.List().Quota, errs.List().Space} {
+			if errors.Is(err, knownErr) {
+				return knownErr
+			}
+		}
+
+		return errs.List().SpaceCheck
 	}
 
+	declared := req.FileSize
+
+	var uploaded int64
+
+	// reservation is made for declared size, space that isn't stored is returned when upload ends
+	defer func() {
+		quota.Release(network, spAddress, int64(declared))
+		quota.Add(network, spAddress, uploaded)
+
+		notStored := int64(declared) - uploaded
+		if notStored <= 0 {
+			return
+		}
+
+		err := releaseSpace(notStored)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+		}
+	}()
+
 	if dirStat == nil {
 		err = os.MkdirAll(pathToSpFiles, 0700)
 		if err != nil {
 			logger.Log(logger.MarkLocation(location, err))
-			return errors.New("couldn't create storage")
+			return errs.List().Internal
 		}
 	}
 
@@ -133,18 +367,40 @@ func (r *rpcServer) UploadFile(stream pb.NodeService_UploadFileServer) error {
 			return err
 		}
 
+		_, err = paths.PartFile(network, spAddress, req.FileName)
+		if err != nil {
+			return errs.List().FileName
+		}
+
+		if uploaded+int64(len(req.ChunkData)) > int64(declared) {
+			return errs.List().FileSize
+		}
+
+		err = bandwidth.Wait(stream.Context(), bandwidth.Upload, spAddress, false, len(req.ChunkData))
+		if err != nil {
+			return err
+		}
+
 		err = spFiles.SaveChunk(pathToSpFiles, req.FileName, req.ChunkData)
 		if err != nil {
-			logger.Log(logger.MarkLocation(location, err))
-			return errors.New("couldn't save file")
+			logger.Error(logger.MarkLocation(location, err), logger.Fields{"network": network, "sp": spAddress, "file": req.FileName})
+			return errs.List().FileSave
 		}
 
+		traffic.AddUploaded(network, spAddress, uint64(len(req.ChunkData)))
+		metrics.UploadBytes.Add(float64(len(req.ChunkData)), network)
+		uploaded += int64(len(req.ChunkData))
+
 		fmt.Println("saved file:", req.FileName)
 
 	}
 
 	stream.SendAndClose(&pb.Response{Msg: "saved"})
 
+	metrics.Uploads.Inc(network)
+	metrics.UploadDuration.ObserveSince(start, network)
+	telemetry.Stat(telemetry.Upload, network, spAddress, remoteAddress(stream.Context()), uploaded)
+
 	return nil
 }
 
@@ -152,28 +408,43 @@ func (r *rpcServer) UploadFile(stream pb.NodeService_UploadFileServer) error {
 
 func (r *rpcServer) DownloadFile(req *pb.DownloadRequest, srv pb.NodeService_DownloadFileServer) error {
 
-	err := sign.Check(req.SpAddress, req.SignedAddress, sha256.Sum256([]byte(req.SpAddress)))
+	start := time.Now()
+
+	err := checkAuth(srv.Context(), req.Sign, "DownloadFile", req.Network, req.SpAddress, sha256.Sum256([]byte(req.SpAddress+strings.Join(req.FileNames, ""))))
 	if err != nil {
 		return err
 	}
 
 	err = networks.Check(req.Network)
+	if err != nil {
+		return errs.List().Network
+	}
+
+	err = addTrafficReceipt(req.UpdatedTraffic, req.Network, req.SpAddress)
 	if err != nil {
 		return err
 	}
 
-	pathToStorage := filepath.Join(paths.List().Storages[0], req.Network, req.SpAddress)
+	var downloaded int64
 
 	for _, fileName := range req.FileNames {
 
-		pathToFile := filepath.Join(pathToStorage, fileName)
+		pathToFile, err := paths.PartFile(req.Network, req.SpAddress, fileName)
+		if err != nil {
+			return errs.List().FileName
+		}
+
+		bytes, err := readPart(pathToFile)
+		if err != nil {
+			return err
+		}
 
-		_, err := os.Stat(pathToFile)
+		err = reserveTraffic(req.Network, req.SpAddress, len(bytes))
 		if err != nil {
 			return err
 		}
 
-		bytes, err := os.ReadFile(pathToFile)
+		err = bandwidth.Wait(srv.Context(), bandwidth.Download, req.SpAddress, false, len(bytes))
 		if err != nil {
 			return err
 		}
@@ -183,10 +454,17 @@ func (r *rpcServer) DownloadFile(req *pb.DownloadRequest, srv pb.NodeService_Dow
 			return err
 		}
 
+		metrics.DownloadBytes.Add(float64(len(bytes)), req.Network, "false")
+		downloaded += int64(len(bytes))
+
 		fmt.Println("serving file:", fileName)
 
 	}
 
+	metrics.Downloads.Inc(req.Network, "false")
+	metrics.DownloadDuration.ObserveSince(start, req.Network, "false")
+	telemetry.Stat(telemetry.Download, req.Network, req.SpAddress, remoteAddress(srv.Context()), downloaded)
+
 	return nil
 }
 
@@ -194,23 +472,75 @@ func (r *rpcServer) DownloadFile(req *pb.DownloadRequest, srv pb.NodeService_Dow
 
 func (r *rpcServer) GatewayDownloadFile(req *pb.GatewayDownloadRequest, srv pb.NodeService_GatewayDownloadFileServer) error {
 
-	err := sign.Check(req.GatewayAddress, req.SignedGatewayAddress, sha256.Sum256([]byte(req.GatewayAddress)))
+	const location = "rpcserver.GatewayDownloadFile ->"
+
+	start := time.Now()
+
+	err := checkAuth(srv.Context(), req.Sign, "GatewayDownloadFile", req.Network, req.GatewayAddress, sha256.Sum256([]byte(req.SpAddress+strings.Join(req.FileNames, ""))))
 	if err != nil {
 		return err
 	}
 
-	pathToStorage := filepath.Join(paths.List().Storages[0], req.Network, req.SpAddress)
+	err = networks.Check(req.Network)
+	if err != nil {
+		return errs.List().Network
+	}
+
+	nodeConfig, err := config.Read()
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+		return errs.List().Internal
+	}
+
+	err = gateway.Authorize(nodeConfig.Gateways, req.GatewayAddress)
+	if err != nil {
+		if !errors.Is(err, errs.List().Gateway) {
+			logger.Log(logger.MarkLocation(location, err))
+			return errs.List().Internal
+		}
+
+		return errs.List().Gateway
+	}
+
+	if gatewayCertRequired && !verifiedPeer(srv.Context()) {
+		return errs.List().Gateway
+	}
+
+	// token check ignores case of the address, but files are looked up by it
+	if common.HexToAddress(req.SpAddress).Hex() != req.SpAddress {
+		return errs.List().Argument
+	}
+
+	err = gateway.CheckToken(req.Token, req.GatewayAddress, req.Network, req.SpAddress, req.FileNames)
+	if err != nil {
+		return errs.List().Gateway
+	}
+
+	err = addTrafficReceipt(req.UpdatedTraffic, req.Network, req.SpAddress)
+	if err != nil {
+		return err
+	}
+
+	var downloaded int64
 
 	for _, fileName := range req.FileNames {
 
-		pathToFile := filepath.Join(pathToStorage, fileName)
+		pathToFile, err := paths.PartFile(req.Network, req.SpAddress, fileName)
+		if err != nil {
+			return errs.List().FileName
+		}
+
+		bytes, err := readPart(pathToFile)
+		if err != nil {
+			return err
+		}
 
-		_, err := os.Stat(pathToFile)
+		err = reserveTraffic(req.Network, req.SpAddress, len(bytes))
 		if err != nil {
 			return err
 		}
 
-		bytes, err := os.ReadFile(pathToFile)
+		err = bandwidth.Wait(srv.Context(), bandwidth.Download, req.SpAddress, true, len(bytes))
 		if err != nil {
 			return err
 		}
@@ -220,31 +550,61 @@ func (r *rpcServer) GatewayDownloadFile(req *pb.GatewayDownloadRequest, srv pb.N
 			return err
 		}
 
+		metrics.DownloadBytes.Add(float64(len(bytes)), req.Network, "true")
+		downloaded += int64(len(bytes))
+
 		fmt.Println("serving file:", fileName)
 
 	}
 
+	metrics.Downloads.Inc(req.Network, "true")
+	metrics.DownloadDuration.ObserveSince(start, req.Network, "true")
+	telemetry.Stat(telemetry.Download, req.Network, req.SpAddress, remoteAddress(srv.Context()), downloaded)
+
 	return nil
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-func (r *rpcServer) UpdateFs(ctx context.Context, req *pb.FsInfo) (*pb.Response, error) {
+func (r *rpcServer) UpdateFs(ctx context.Context, req *pb.FsInfo) (*pb.FileSystemStateResponse, error) {
 
 	const location = "rpcserver.UpdateFs ->"
 
+	err := checkAuth(ctx, req.Sign, "UpdateFs", req.Network, req.SpAddress, sha256.Sum256([]byte(req.Signature)))
+	if err != nil {
+		return &pb.FileSystemStateResponse{Msg: "failed"}, err
+	}
+
+	err = networks.Check(req.Network)
+	if err != nil {
+		return &pb.FileSystemStateResponse{Msg: "failed"}, errs.List().Network
+	}
+
+	err = paths.CheckAddress(req.SpAddress)
+	if err != nil {
+		return &pb.FileSystemStateResponse{Msg: "failed"}, errs.List().Argument
+	}
+
+	// leaves are hashes of parts, other values can't be stored in fs info
+	for _, partName := range req.NewFs {
+		err = paths.CheckPartName(partName)
+		if err != nil {
+			return &pb.FileSystemStateResponse{Msg: "failed"}, errs.List().Argument
+		}
+	}
+
 	sort.Strings(req.NewFs)
 
 	fsRootHash, fsTree, err := hash.CalcRoot(req.NewFs)
 	if err != nil {
 		logger.Log(logger.MarkLocation(location, err))
-		return &pb.Response{Msg: "failed"}, errors.New("couldn't save fsys info")
+		return &pb.FileSystemStateResponse{Msg: "failed"}, errs.List().FsUpdate
 	}
 
 	fsRootBytes, err := hex.DecodeString(fsRootHash)
 	if err != nil {
 		logger.Log(logger.MarkLocation(location, err))
-		return &pb.Response{Msg: "failed"}, errors.New("couldn't save fsys info")
+		return &pb.FileSystemStateResponse{Msg: "failed"}, errs.List().FsUpdate
 	}
 
 	nonceBytes := make([]byte, 4)
@@ -267,59 +627,396 @@ func (r *rpcServer) UpdateFs(ctx context.Context, req *pb.FsInfo) (*pb.Response,
 
 	err = sign.Check(req.SpAddress, req.Signature, sha256.Sum256(fsRootStorageNonceBytes))
 	if err != nil {
-		logger.Log(logger.MarkLocation(location, err))
-		return &pb.Response{Msg: "failed"}, errors.New("couldn't save fsys info")
+		return &pb.FileSystemStateResponse{Msg: "failed"}, errs.List().Signature
 	}
 
 	err = fsysInfo.Save(req, fsTree)
+	if errors.Is(err, errs.List().FsOutdated) {
+		return &pb.FileSystemStateResponse{Msg: "outdated", State: pb.FileSystemState_OLD}, nil
+	}
+
 	if err != nil {
 		logger.Log(logger.MarkLocation(location, err))
-		return &pb.Response{Msg: "failed"}, errors.New("couldn't save fsys info")
+		return &pb.FileSystemStateResponse{Msg: "failed"}, errs.List().FsUpdate
 	}
 
-	return &pb.Response{Msg: "updated"}, nil
+	resp, err := compareStoredParts(req)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+		return &pb.FileSystemStateResponse{Msg: "failed"}, errs.List().Internal
+	}
+
+	return resp, nil
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-//Checks if space enough for uploading a file and reserves space
+// compareStoredParts compares parts of the updated fs with parts stored on disk.
+// Stored parts that aren't referenced by any kept fs snapshot are passed to cleaner.
+func compareStoredParts(req *pb.FsInfo) (*pb.FileSystemStateResponse, error) {
+	const location = "rpcserver.compareStoredParts ->"
 
-func checkAndReserveSpace(fileSize uint32) error {
-	const location = "rpcserver.checkAndReserveSpace"
+	pathToSpFiles, err := paths.SpFilesDir(req.Network, req.SpAddress)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	storedParts, err := spFiles.PartNames(pathToSpFiles)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
 
-	var nodeConfig nodeTypes.Config
+	stored := make(map[string]bool, len(storedParts))
 
-	mutex.Lock()
-	defer mutex.Unlock()
+	for _, partName := range storedParts {
+		stored[partName] = true
+	}
 
-	confFile, fileBytes, err := nodeFile.Read(paths.List().ConfigFile)
+	newFs := make(map[string]bool, len(req.NewFs))
+
+	resp := &pb.FileSystemStateResponse{
+		Msg:          "updated",
+		State:        pb.FileSystemState_ACTUAL,
+		MissingParts: []string{},
+		ExtraParts:   []string{},
+	}
+
+	for _, partName := range req.NewFs {
+		newFs[partName] = true
+
+		if partName == emptyPartName || stored[partName] {
+			continue
+		}
+
+		resp.MissingParts = append(resp.MissingParts, partName)
+	}
+
+	for _, partName := range storedParts {
+		if !newFs[partName] {
+			resp.ExtraParts = append(resp.ExtraParts, partName)
+		}
+	}
+
+	if len(resp.MissingParts) != 0 {
+		resp.State = pb.FileSystemState_INCOMPLETE
+	}
+
+	if len(resp.ExtraParts) == 0 {
+		return resp, nil
+	}
+
+	referenced, err := fsysInfo.Leaves(req.Network, req.SpAddress)
 	if err != nil {
-		mutex.Unlock()
-		return logger.MarkLocation(location, err)
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	orphaned := make([]string, 0, len(resp.ExtraParts))
+
+	for _, partName := range resp.ExtraParts {
+		if !referenced[partName] {
+			orphaned = append(orphaned, partName)
+		}
+	}
+
+	cleaner.MarkOrphaned(req.Network, req.SpAddress, orphaned)
+
+	return resp, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// UploadFS stores storage provider's encrypted filesystem backup. First message contains signed backup info.
+func (r *rpcServer) UploadFS(stream pb.NodeService_UploadFSServer) error {
+
+	const location = "rpcserver.UploadFS ->"
+
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	info := req.Info
+
+	if info == nil {
+		return errs.List().Argument
 	}
-	defer confFile.Close()
 
-	err = json.Unmarshal(fileBytes, &nodeConfig)
+	err = paths.CheckAddress(info.SpAddress)
 	if err != nil {
-		return logger.MarkLocation(location, err)
+		return errs.List().Argument
 	}
 
-	totalNodeSpace := int64(nodeConfig.StorageLimit * 1024 * 1024 * 1024) // convert to bytes
+	err = networks.Check(info.Network)
+	if err != nil {
+		return errs.List().Network
+	}
 
-	nodeConfig.UsedStorageSpace += int64(fileSize)
+	err = checkAuth(stream.Context(), req.Sign, "UploadFS", info.Network, info.SpAddress, sha256.Sum256([]byte(info.Signature)))
+	if err != nil {
+		return err
+	}
+
+	infoHash, err := backupInfoHash(info)
+	if err != nil {
+		return errs.List().Argument
+	}
+
+	err = sign.Check(info.SpAddress, info.Signature, infoHash)
+	if err != nil {
+		return errs.List().Signature
+	}
+
+	if info.Size > fsysInfo.MaxBackupSize {
+		return errs.List().FileSize
+	}
 
-	if nodeConfig.UsedStorageSpace > totalNodeSpace {
+	storedInfo, err := fsysInfo.BackupInfo(info.Network, info.SpAddress)
+	if err == nil && info.Version <= storedInfo.Version {
+		return errs.List().FsOutdated
+	}
+
+	backupInfo := nodeTypes.FsBackupInfo{
+		Network:   info.Network,
+		Version:   info.Version,
+		Size:      info.Size,
+		Hash:      info.Hash,
+		Signature: info.Signature,
+	}
+
+	err = fsysInfo.BackUpSPFsys(info.Network, info.SpAddress, backupInfo, &fsChunkReader{stream: stream, spAddress: info.SpAddress, buf: req.ChunkData})
+	if err != nil {
+		// known errors and statuses of upload stream are passed to client, other ones are hidden by status interceptor
 		return logger.MarkLocation(location, err)
 	}
 
-	avaliableSpaceLeft := totalNodeSpace - nodeConfig.UsedStorageSpace
+	fmt.Println("saved fs backup of", info.SpAddress, "version", info.Version)
+
+	return stream.SendAndClose(&pb.Response{Msg: "saved"})
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// DownloadFS sends storage provider's filesystem backup. First message contains backup info signed by storage provider.
+func (r *rpcServer) DownloadFS(req *pb.DownloadFsRequest, srv pb.NodeService_DownloadFSServer) error {
+
+	const location = "rpcserver.DownloadFS ->"
+
+	err := paths.CheckAddress(req.SpAddress)
+	if err != nil {
+		return errs.List().Argument
+	}
+
+	err = checkAuth(srv.Context(), req.Sign, "DownloadFS", req.Network, req.SpAddress, sha256.Sum256([]byte(req.SpAddress)))
+	if err != nil {
+		return err
+	}
+
+	err = networks.Check(req.Network)
+	if err != nil {
+		return errs.List().Network
+	}
+
+	file, info, err := fsysInfo.OpenBackup(req.Network, req.SpAddress)
+	if err != nil {
+		if errors.Is(err, errs.List().StorageSystem) {
+			return errs.List().StorageSystem
+		}
+
+		logger.Log(logger.MarkLocation(location, err))
+		return errs.List().Internal
+	}
+
+	defer file.Close()
+
+	err = srv.Send(&pb.DownloadFsResponse{Info: &pb.FsBackupInfo{
+		SpAddress: req.SpAddress,
+		Network:   info.Network,
+		Version:   info.Version,
+		Size:      info.Size,
+		Hash:      info.Hash,
+		Signature: info.Signature,
+	}})
+	if err != nil {
+		return err
+	}
+
+	chunk := make([]byte, fsChunkSize)
+
+	for {
+		n, err := file.Read(chunk)
+		if n > 0 {
+			waitErr := bandwidth.Wait(srv.Context(), bandwidth.Download, req.SpAddress, false, n)
+			if waitErr != nil {
+				return waitErr
+			}
+
+			sendErr := srv.Send(&pb.DownloadFsResponse{ChunkData: chunk[:n]})
+			if sendErr != nil {
+				return sendErr
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+			return errs.List().Internal
+		}
+	}
+
+	fmt.Println("serving fs backup of", req.SpAddress)
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// reserveTraffic counts served part to storage provider's traffic.
+func reserveTraffic(network, spAddress string, size int) error {
+	const location = "rpcserver.reserveTraffic->"
+
+	err := traffic.Reserve(network, spAddress, uint64(size))
+	if err != nil {
+		if errors.Is(err, errs.List().Traffic) {
+			return errs.List().Traffic
+		}
+
+		logger.Log(logger.MarkLocation(location, err))
+		return errs.List().Internal
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// readPart reads stored part, missing part is reported with NotFound error.
+func readPart(pathToFile string) ([]byte, error) {
+	const location = "rpcserver.readPart->"
+
+	bytes, err := os.ReadFile(pathToFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errs.List().NotFound
+		}
+
+		logger.Log(logger.MarkLocation(location, err))
+		return nil, errs.List().Internal
+	}
+
+	return bytes, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// backupInfoHash returns hash of backup info fields that storage provider signs.
+func backupInfoHash(info *pb.FsBackupInfo) ([32]byte, error) {
+	backupHash, err := hex.DecodeString(info.Hash)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	if len(backupHash) != sha256.Size {
+		return [32]byte{}, errs.List().Argument
+	}
+
+	sizes := make([]byte, 8)
+	binary.BigEndian.PutUint32(sizes[:4], info.Version)
+	binary.BigEndian.PutUint32(sizes[4:], info.Size)
+
+	data := make([]byte, 0, len(info.SpAddress)+len(info.Network)+len(sizes)+len(backupHash))
+	data = append(data, info.SpAddress...)
+	data = append(data, info.Network...)
+	data = append(data, sizes...)
+	data = append(data, backupHash...)
+
+	return sha256.Sum256(data), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// fsChunkReader reads filesystem backup chunks from upload stream.
+type fsChunkReader struct {
+	stream    pb.NodeService_UploadFSServer
+	spAddress string
+	buf       []byte
+}
+
+func (r *fsChunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+
+		err = bandwidth.Wait(r.stream.Context(), bandwidth.Upload, r.spAddress, false, len(req.ChunkData))
+		if err != nil {
+			return 0, err
+		}
+
+		r.buf = req.ChunkData
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
 
-	if avaliableSpaceLeft < int64(1024*1024*100) { // 100 Mib
-		fmt.Println("Shared storage memory is running low,", avaliableSpaceLeft/(1024*1024), "MB of space is avaliable")
-		fmt.Println("You may need additional space for storing data. Total shared space can be changed in account configuration")
+	return n, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+//Checks if space enough and storage provider's quotas allow uploading a file and reserves space
+
+func checkAndReserveSpace(network, spAddress string, fileSize uint32) error {
+	const location = "rpcserver.checkAndReserveSpace"
+
+	err := config.Update(func(nodeConfig *nodeTypes.Config) error {
+		totalNodeSpace := int64(nodeConfig.StorageLimit) * 1024 * 1024 * 1024 // convert to bytes
+
+		nodeConfig.UsedStorageSpace += int64(fileSize)
+
+		if nodeConfig.UsedStorageSpace > totalNodeSpace {
+			return errs.List().Space
+		}
+
+		err := quota.Reserve(*nodeConfig, network, spAddress, int64(fileSize))
+		if err != nil {
+			return err
+		}
+
+		avaliableSpaceLeft := totalNodeSpace - nodeConfig.UsedStorageSpace
+
+		if avaliableSpaceLeft < int64(1024*1024*100) { // 100 Mib
+			fmt.Println("Shared storage memory is running low,", avaliableSpaceLeft/(1024*1024), "MB of space is avaliable")
+			fmt.Println("You may need additional space for storing data. Total shared space can be changed in account configuration")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return logger.MarkLocation(location, err)
 	}
 
-	err = config.Save(confFile, nodeConfig)
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// releaseSpace returns node space that was reserved for upload but not stored.
+func releaseSpace(size int64) error {
+	const location = "rpcserver.releaseSpace->"
+
+	err := config.Update(func(nodeConfig *nodeTypes.Config) error {
+		nodeConfig.UsedStorageSpace -= size
+
+		if nodeConfig.UsedStorageSpace < 0 {
+			nodeConfig.UsedStorageSpace = 0
+		}
+
+		return nil
+	})
 	if err != nil {
 		return logger.MarkLocation(location, err)
 	}
@@ -328,3 +1025,20 @@ func checkAndReserveSpace(fileSize uint32) error {
 }
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// verifiedPeer reports if client presented certificate signed by one of configured client CAs.
+func verifiedPeer(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return false
+	}
+
+	return len(tlsInfo.State.VerifiedChains) > 0
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,321 @@
+package rpcserver_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/auth"
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/errs"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/pb"
+	"github.com/DeNetPRO/src/rpcserver"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	network  = "kovan"
+	partName = "6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b"
+)
+
+func TestMain(m *testing.M) {
+	tstpkg.TestModeOn()
+	defer tstpkg.TestModeOff()
+
+	err := paths.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = config.Create(tstpkg.Data().AccAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	exitVal := m.Run()
+
+	err = os.RemoveAll(paths.List().WorkDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitVal)
+}
+
+func startServer(t *testing.T, limits nodeTypes.LimitsConfig) pb.NodeServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+
+	server, err := rpcserver.NewServer(nodeTypes.Config{Limits: limits})
+	require.NoError(t, err)
+
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewNodeServiceClient(conn)
+}
+
+// signRequest signs request payload with a fresh nonce issued by the server.
+func signRequest(t *testing.T, client pb.NodeServiceClient, key *ecdsa.PrivateKey, method string, payloadHash [32]byte) *pb.Signature {
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	nonce, err := client.GetNonce(context.Background(), &pb.NonceRequest{Signer: signer.Bytes()})
+	require.NoError(t, err)
+
+	timestamp := time.Now().Unix()
+
+	digest := auth.Digest(method, network, payloadHash, nonce.Nonce, timestamp)
+
+	signedBytes, err := crypto.Sign(digest[:], key)
+	require.NoError(t, err)
+
+	return &pb.Signature{Signer: signer.Bytes(), Nonce: nonce.Nonce, SignedBytes: signedBytes, Timestamp: timestamp}
+}
+
+func requireErr(t *testing.T, expected, err error) {
+	st, _ := errs.Status(expected)
+
+	require.Equal(t, st.Code(), status.Code(err))
+	require.Equal(t, expected.Error(), status.Convert(err).Message())
+}
+
+func uploadRequest(t *testing.T, client pb.NodeServiceClient, key *ecdsa.PrivateKey, fileSize uint32) *pb.UploadRequest {
+	spAddress := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	fileSizeBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(fileSizeBytes, fileSize)
+
+	sign := signRequest(t, client, key, "UploadFile", sha256.Sum256(append([]byte(spAddress), fileSizeBytes...)))
+
+	return &pb.UploadRequest{FileSize: fileSize, SpAddress: spAddress, Network: network, Sign: sign}
+}
+
+func TestRateLimit(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{IPRate: 0.001, IPBurst: 1})
+
+	req := &pb.NonceRequest{Signer: make([]byte, 20)}
+
+	_, err := client.GetNonce(context.Background(), req)
+	require.NoError(t, err)
+
+	_, err = client.GetNonce(context.Background(), req)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestForgedSpRateLimit(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{SpRate: 0.001, SpBurst: 1})
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	forgerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	spAddress := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	trafficRequest := func(key *ecdsa.PrivateKey) *pb.TrafficInfo {
+		return &pb.TrafficInfo{
+			Network:   network,
+			SpAddress: spAddress,
+			Auth:      signRequest(t, client, key, "GetTrafficInfo", sha256.Sum256([]byte(spAddress))),
+		}
+	}
+
+	// requests claim victim's address, but are signed by another key
+	for i := 0; i < 3; i++ {
+		_, err = client.GetTrafficInfo(context.Background(), trafficRequest(forgerKey))
+		requireErr(t, errs.List().Signature, err)
+	}
+
+	_, err = client.GetTrafficInfo(context.Background(), trafficRequest(key))
+	require.NoError(t, err)
+
+	_, err = client.GetTrafficInfo(context.Background(), trafficRequest(key))
+	requireErr(t, errs.List().RateLimit, err)
+}
+
+func TestStreamSize(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{MaxStreamSize: 16})
+
+	stream, err := client.UploadFile(context.Background())
+	require.NoError(t, err)
+
+	err = stream.Send(&pb.UploadRequest{ChunkData: make([]byte, 64)})
+	require.NoError(t, err)
+
+	_, err = stream.CloseAndRecv()
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Contains(t, status.Convert(err).Message(), "stream size")
+}
+
+func TestIdleStream(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{IdleTimeout: 1, MaxConcurrentRequests: 1})
+
+	stream, err := client.UploadFile(context.Background())
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	// client sends nothing and doesn't close the stream
+	err = stream.RecvMsg(&pb.Response{})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.WithinDuration(t, start.Add(time.Second), time.Now(), time.Second)
+
+	// handler has finished and released its slot
+	_, err = client.GetNonce(context.Background(), &pb.NonceRequest{Signer: make([]byte, 20)})
+	require.NoError(t, err)
+}
+
+func TestUploadOverDeclaredSize(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{})
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	before, err := config.Read()
+	require.NoError(t, err)
+
+	stream, err := client.UploadFile(context.Background())
+	require.NoError(t, err)
+
+	err = stream.Send(uploadRequest(t, client, key, 4))
+	require.NoError(t, err)
+
+	err = stream.Send(&pb.UploadRequest{FileName: partName, ChunkData: make([]byte, 8)})
+	require.NoError(t, err)
+
+	_, err = stream.CloseAndRecv()
+	requireErr(t, errs.List().FileSize, err)
+
+	after, err := config.Read()
+	require.NoError(t, err)
+	require.Equal(t, before.UsedStorageSpace, after.UsedStorageSpace)
+}
+
+func TestUploadQuota(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{})
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	setQuota := func(quota nodeTypes.Quota) {
+		err := config.Update(func(nodeConfig *nodeTypes.Config) error {
+			nodeConfig.Quotas.Default = quota
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	setQuota(nodeTypes.Quota{Bytes: 10})
+	defer setQuota(nodeTypes.Quota{})
+
+	stream, err := client.UploadFile(context.Background())
+	require.NoError(t, err)
+
+	err = stream.Send(uploadRequest(t, client, key, 100))
+	require.NoError(t, err)
+
+	_, err = stream.CloseAndRecv()
+	requireErr(t, errs.List().Quota, err)
+}
+
+func TestSignerMismatch(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{})
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	req := uploadRequest(t, client, key, 4)
+	req.Sign = uploadRequest(t, client, otherKey, 4).Sign
+
+	stream, err := client.UploadFile(context.Background())
+	require.NoError(t, err)
+
+	err = stream.Send(req)
+	require.NoError(t, err)
+
+	_, err = stream.CloseAndRecv()
+	requireErr(t, errs.List().Signature, err)
+
+	// address must be passed in checksum form, the one that is signed
+	spAddress := strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+
+	_, err = client.GetTrafficInfo(context.Background(), &pb.TrafficInfo{
+		Network:   network,
+		SpAddress: spAddress,
+		Auth:      signRequest(t, client, key, "GetTrafficInfo", sha256.Sum256([]byte(spAddress))),
+	})
+	requireErr(t, errs.List().Signature, err)
+}
+
+func TestNonceReplay(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{})
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	spAddress := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	req := &pb.TrafficInfo{
+		Network:   network,
+		SpAddress: spAddress,
+		Auth:      signRequest(t, client, key, "GetTrafficInfo", sha256.Sum256([]byte(spAddress))),
+	}
+
+	_, err = client.GetTrafficInfo(context.Background(), req)
+	require.NoError(t, err)
+
+	_, err = client.GetTrafficInfo(context.Background(), req)
+	requireErr(t, errs.List().Nonce, err)
+}
+
+func TestMalformedNewFs(t *testing.T) {
+	client := startServer(t, nodeTypes.LimitsConfig{})
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	for _, newFs := range [][]string{{"../" + partName[3:]}, {partName, partName[:32]}, {partName + "00"}} {
+		req := &pb.FsInfo{
+			Network:   network,
+			SpAddress: crypto.PubkeyToAddress(key.PublicKey).Hex(),
+			Nonce:     1,
+			Storage:   1,
+			NewFs:     newFs,
+			Signature: "fs signature",
+		}
+
+		req.Sign = signRequest(t, client, key, "UpdateFs", sha256.Sum256([]byte(req.Signature)))
+
+		_, err = client.UpdateFs(context.Background(), req)
+		requireErr(t, errs.List().Argument, err)
+	}
+}
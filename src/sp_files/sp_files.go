@@ -1,10 +1,12 @@
 package spfiles
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 
 	"github.com/DeNetPRO/src/logger"
+	nodeFile "github.com/DeNetPRO/src/node_file"
 	"github.com/DeNetPRO/src/paths"
 )
 
@@ -33,6 +35,33 @@ func SaveChunk(pathToSpFiles, fileName string, spFileChunk []byte) error {
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
+// PartNames returns names of file parts stored in storage provider's directory.
+// Empty list is returned if directory doesn't exist.
+func PartNames(pathToSpFiles string) ([]string, error) {
+	const location = "files.PartNames->"
+
+	dirFiles, err := nodeFile.ReadDirFiles(pathToSpFiles)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	partNames := make([]string, 0, len(dirFiles))
+
+	for _, f := range dirFiles {
+		if !f.IsDir() && paths.CheckPartName(f.Name()) == nil {
+			partNames = append(partNames, f.Name())
+		}
+	}
+
+	return partNames, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
 // DeleteParts deletes parts of the file that wasn't fully uploaded to the node for some reason.
 func deleteParts(addressPath string, fileHashes []string) {
 	logger.Log("deleting file parts after error...")
@@ -46,9 +75,13 @@ func deleteParts(addressPath string, fileHashes []string) {
 
 // ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
 
-// Return storage provider filesystem path
-func SearchStorageFilesystem(spAddress string) (string, bool) {
-	path := filepath.Join(paths.List().SysDir, spAddress)
+// Return storage provider filesystem backup path, found is false if backup doesn't exist
+func SearchStorageFilesystem(network, spAddress string) (string, bool) {
+	if paths.CheckAddress(spAddress) != nil {
+		return "", false
+	}
+
+	path := filepath.Join(paths.List().SysDir, network, spAddress)
 	stat, _ := os.Stat(path)
 	if stat == nil {
 		return "", false
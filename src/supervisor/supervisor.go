@@ -0,0 +1,233 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/DeNetPRO/src/health"
+	"github.com/DeNetPRO/src/logger"
+)
+
+// Worker Run must return when ctx is done. Any other return, including panic, is a failure
+// and worker is started again after backoff.
+type Worker struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Options zero values are replaced with defaults. Reload is called on SIGHUP.
+type Options struct {
+	MinBackoff      time.Duration
+	MaxBackoff      time.Duration
+	ShutdownTimeout time.Duration
+	Reload          func()
+}
+
+const (
+	defaultMinBackoff      = time.Second
+	defaultMaxBackoff      = 5 * time.Minute
+	defaultShutdownTimeout = 2 * time.Minute
+
+	// worker that ran that long before failure is restarted with minimal backoff again
+	resetBackoffAfter = 10 * time.Minute
+)
+
+var ErrShutdownTimeout = errors.New("workers didn't stop in time")
+
+// running is current run of a worker.
+type running struct {
+	cancel    context.CancelFunc
+	restarted int32
+}
+
+var (
+	mutex sync.Mutex
+	runs  = map[string]*running{}
+)
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Run runs workers until ctx is done or SIGINT/SIGTERM is received, then waits until workers stop.
+// Second signal or shutdown timeout stops waiting.
+func Run(ctx context.Context, opts Options, workers ...Worker) error {
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultMinBackoff
+	}
+
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+
+	if opts.ShutdownTimeout <= 0 {
+		opts.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	var wg sync.WaitGroup
+
+	for _, w := range workers {
+		wg.Add(1)
+
+		go func(w Worker) {
+			defer wg.Done()
+			supervise(ctx, opts, w)
+		}(w)
+	}
+
+	stopped := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	for ctx.Err() == nil {
+		select {
+		case sig := <-signals:
+			if sig != syscall.SIGHUP {
+				fmt.Println("received", sig, "shutting down...")
+				cancel()
+				break
+			}
+
+			if opts.Reload == nil {
+				fmt.Println("received", sig, "reload is not supported")
+				break
+			}
+
+			opts.Reload()
+
+		case <-ctx.Done():
+		}
+	}
+
+	timer := time.NewTimer(opts.ShutdownTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stopped:
+			return nil
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				continue
+			}
+
+			return ErrShutdownTimeout
+		case <-timer.C:
+			return ErrShutdownTimeout
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func supervise(ctx context.Context, opts Options, w Worker) {
+	const location = "supervisor.supervise->"
+
+	delay := opts.MinBackoff
+
+	for {
+		start := time.Now()
+
+		runCtx, cancel := context.WithCancel(ctx)
+		current := &running{cancel: cancel}
+
+		mutex.Lock()
+		runs[w.Name] = current
+		mutex.Unlock()
+
+		err := run(runCtx, w)
+
+		cancel()
+
+		mutex.Lock()
+		delete(runs, w.Name)
+		mutex.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if atomic.LoadInt32(&current.restarted) == 1 {
+			fmt.Println(w.Name, "restarted")
+			delay = opts.MinBackoff
+			continue
+		}
+
+		if err == nil {
+			err = errors.New("worker returned")
+		}
+
+		health.Stop(w.Name, err)
+		logger.Error(logger.MarkLocation(location, err), logger.Fields{"worker": w.Name})
+
+		if time.Since(start) >= resetBackoffAfter {
+			delay = opts.MinBackoff
+		}
+
+		fmt.Println(w.Name, "stopped, restarting in", delay)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		delay *= 2
+		if delay > opts.MaxBackoff {
+			delay = opts.MaxBackoff
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Restart stops current run of worker and starts it again without backoff, e.g. to apply new config.
+// It returns false if worker is not running.
+func Restart(name string) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	current, ok := runs[name]
+	if !ok {
+		return false
+	}
+
+	atomic.StoreInt32(&current.restarted, 1)
+	current.cancel()
+
+	return true
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// run runs worker once, panic is returned as error.
+func run(ctx context.Context, w Worker) (err error) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return w.Run(ctx)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
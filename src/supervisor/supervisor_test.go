@@ -0,0 +1,124 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/supervisor"
+	"github.com/stretchr/testify/require"
+)
+
+var testOpts = supervisor.Options{
+	MinBackoff:      10 * time.Millisecond,
+	MaxBackoff:      40 * time.Millisecond,
+	ShutdownTimeout: time.Second,
+}
+
+func TestRestartOnFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var failing, panicking int32
+
+	workers := []supervisor.Worker{
+		{
+			Name: "failing",
+			Run: func(ctx context.Context) error {
+				atomic.AddInt32(&failing, 1)
+				return errors.New("failed")
+			},
+		},
+		{
+			Name: "panicking",
+			Run: func(ctx context.Context) error {
+				if atomic.AddInt32(&panicking, 1) == 1 {
+					panic("worker panic")
+				}
+
+				<-ctx.Done()
+				return nil
+			},
+		},
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- supervisor.Run(ctx, testOpts, workers...)
+	}()
+
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("supervisor didn't stop")
+	}
+
+	// backoff grows, so failing worker is restarted only several times
+	require.Greater(t, atomic.LoadInt32(&failing), int32(2))
+	require.Less(t, atomic.LoadInt32(&failing), int32(15))
+	require.Equal(t, int32(2), atomic.LoadInt32(&panicking))
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stuck := supervisor.Worker{
+		Name: "stuck",
+		Run: func(ctx context.Context) error {
+			time.Sleep(time.Hour)
+			return nil
+		},
+	}
+
+	opts := testOpts
+	opts.ShutdownTimeout = 50 * time.Millisecond
+
+	cancel()
+
+	err := supervisor.Run(ctx, opts, stuck)
+	require.ErrorIs(t, err, supervisor.ErrShutdownTimeout)
+}
+
+func TestRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs int32
+
+	worker := supervisor.Worker{
+		Name: "restarted",
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			<-ctx.Done()
+			return nil
+		},
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- supervisor.Run(ctx, testOpts, worker)
+	}()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) == 1
+	}, time.Second, time.Millisecond)
+
+	require.True(t, supervisor.Restart("restarted"))
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) == 2
+	}, time.Second, time.Millisecond)
+
+	require.False(t, supervisor.Restart("unknown"))
+
+	cancel()
+	require.NoError(t, <-done)
+}
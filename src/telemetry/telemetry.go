@@ -0,0 +1,454 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DeNetPRO/src/logger"
+	"github.com/DeNetPRO/src/metrics"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+)
+
+type EventType string
+
+const (
+	Upload   EventType = "upload"
+	Download EventType = "download"
+	Delete   EventType = "delete"
+	Error    EventType = "error"
+)
+
+const (
+	// DefaultEndpoint is used when endpoint is not set in config. It's reached over plain http,
+	// so payload can be read on the way, see Batch.
+	DefaultEndpoint = "http://68.183.215.241:9091/telemetry"
+
+	SchemaVersion = 1
+
+	maxRetries = 3
+	retryDelay = time.Second
+
+	// salt file keeps secret that addresses are hashed with, it's made on the first start
+	saltFileName = "telemetry.salt"
+	saltSize     = 32
+)
+
+// Event is one record of telemetry payload.
+//
+//	time        unix time in seconds
+//	type        upload, download, delete or error
+//	network     network name, e.g. kovan
+//	sp          storage provider's address, hashed unless addresses are sent
+//	remoteAddr  client's address, only set if addresses are sent
+//	size        bytes transferred or deleted
+//	message     error message, addresses are hashed and ip addresses removed unless addresses are sent
+//	location    error location from logger.MarkLocation
+type Event struct {
+	Time       int64     `json:"time"`
+	Type       EventType `json:"type"`
+	Network    string    `json:"network,omitempty"`
+	SP         string    `json:"sp,omitempty"`
+	RemoteAddr string    `json:"remoteAddr,omitempty"`
+	Size       int64     `json:"size,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	Location   string    `json:"location,omitempty"`
+}
+
+// Batch is the telemetry payload, it's posted to endpoint as json and appended to file as one line.
+// Telemetry is sent to DefaultEndpoint over plain http unless other endpoint is set or sending is disabled.
+//
+//	schema  payload schema version, currently 1
+//	node    node address, hashed unless addresses are sent
+//	sentAt  unix time in seconds
+//	events  list of events
+//
+// Addresses are hashed with HMAC-SHA256 keyed by random secret of the node installation, kept in work dir,
+// so hashes of one node can be correlated with each other, but can't be matched with on-chain addresses.
+type Batch struct {
+	Schema int     `json:"schema"`
+	Node   string  `json:"node"`
+	SentAt int64   `json:"sentAt"`
+	Events []Event `json:"events"`
+}
+
+type sink interface {
+	send(ctx context.Context, batch Batch) error
+	name() string
+}
+
+// DefaultConfig keeps up to 1000 events in queue and sends them by 100 at least every 30 seconds.
+var DefaultConfig = nodeTypes.TelemetryConfig{
+	QueueSize:     1000,
+	BatchSize:     100,
+	FlushInterval: 30,
+}
+
+var (
+	mutex           sync.Mutex
+	telemetryConfig nodeTypes.TelemetryConfig
+	nodeAddress     string
+	salt            []byte
+	sinks           []sink
+	queue           chan Event
+	flushRequests   = make(chan chan struct{})
+	startOnce       sync.Once
+
+	errorReports int32
+
+	regAddr = regexp.MustCompile("0x[0-9a-fA-F]{40}")
+	regIP   = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`)
+)
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Start starts sending events in background. Queue size and batching are set on the first call,
+// later calls only change sinks and anonymisation.
+func Start(address string, conf nodeTypes.TelemetryConfig) {
+	conf = withDefaults(conf)
+
+	SetConfig(conf)
+
+	installSalt, err := loadSalt()
+	if err != nil {
+		logger.Log(logger.MarkLocation("telemetry.Start->", err))
+	}
+
+	mutex.Lock()
+	nodeAddress = strings.ToLower(address)
+	salt = installSalt
+	mutex.Unlock()
+
+	startOnce.Do(func() {
+		mutex.Lock()
+		queue = make(chan Event, conf.QueueSize)
+		mutex.Unlock()
+
+		logger.AddSink(errorSink{})
+
+		go worker(conf.BatchSize, time.Duration(conf.FlushInterval)*time.Second)
+	})
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// SetConfig sets endpoint, file and anonymisation.
+func SetConfig(conf nodeTypes.TelemetryConfig) {
+	conf = withDefaults(conf)
+
+	newSinks := []sink{}
+
+	if !conf.Disabled {
+		newSinks = append(newSinks, newHTTPSink(conf.Endpoint))
+	}
+
+	if conf.File != "" {
+		newSinks = append(newSinks, &fileSink{path: conf.File})
+	}
+
+	mutex.Lock()
+	telemetryConfig = conf
+	sinks = newSinks
+	mutex.Unlock()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// SetErrorReports switches on/off sending of logged errors.
+func SetErrorReports(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+
+	atomic.StoreInt32(&errorReports, value)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func withDefaults(conf nodeTypes.TelemetryConfig) nodeTypes.TelemetryConfig {
+	if conf.Endpoint == "" {
+		conf.Endpoint = DefaultEndpoint
+	}
+
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = DefaultConfig.QueueSize
+	}
+
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = DefaultConfig.BatchSize
+	}
+
+	if conf.FlushInterval <= 0 {
+		conf.FlushInterval = DefaultConfig.FlushInterval
+	}
+
+	return conf
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Stat records transfer or deletion of storage provider's data.
+func Stat(eventType EventType, network, spAddress, remoteAddr string, size int64) {
+	Record(Event{Type: eventType, Network: network, SP: spAddress, RemoteAddr: remoteAddr, Size: size})
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Record anonymises event and puts it in queue. Event is dropped if queue is full or telemetry is not started.
+func Record(event Event) {
+	mutex.Lock()
+	sendAddresses := telemetryConfig.SendAddresses
+	key := salt
+	q := queue
+	mutex.Unlock()
+
+	if q == nil {
+		return
+	}
+
+	if event.Time == 0 {
+		event.Time = time.Now().Unix()
+	}
+
+	if !sendAddresses {
+		event.RemoteAddr = ""
+
+		if event.SP != "" {
+			event.SP = anonymize(key, event.SP)
+		}
+
+		event.Message = scrub(key, event.Message)
+		event.Location = scrub(key, event.Location)
+	}
+
+	select {
+	case q <- event:
+	default:
+		metrics.TelemetryDropped.Inc()
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Flush sends queued events and waits until they are sent or ctx is done.
+func Flush(ctx context.Context) error {
+	mutex.Lock()
+	started := queue != nil
+	mutex.Unlock()
+
+	if !started {
+		return nil
+	}
+
+	done := make(chan struct{})
+
+	select {
+	case flushRequests <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func worker(batchSize int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, batchSize)
+
+	for {
+		select {
+		case event := <-queue:
+			batch = append(batch, event)
+
+			if len(batch) >= batchSize {
+				send(batch)
+				batch = make([]Event, 0, batchSize)
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				send(batch)
+				batch = make([]Event, 0, batchSize)
+			}
+
+		case done := <-flushRequests:
+			batch = drain(batch)
+
+			if len(batch) > 0 {
+				send(batch)
+				batch = make([]Event, 0, batchSize)
+			}
+
+			close(done)
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func drain(batch []Event) []Event {
+	for {
+		select {
+		case event := <-queue:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// send passes batch to each sink, failed sends are retried with growing delay and dropped after that.
+func send(events []Event) {
+	mutex.Lock()
+	current := sinks
+	node := nodeAddress
+	if !telemetryConfig.SendAddresses {
+		node = anonymize(salt, nodeAddress)
+	}
+	mutex.Unlock()
+
+	batch := Batch{
+		Schema: SchemaVersion,
+		Node:   node,
+		SentAt: time.Now().Unix(),
+		Events: events,
+	}
+
+	for _, s := range current {
+		delay := retryDelay
+
+		for attempt := 0; ; attempt++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err := s.send(ctx, batch)
+			cancel()
+
+			if err == nil {
+				break
+			}
+
+			if attempt == maxRetries {
+				metrics.TelemetryDropped.Add(float64(len(events)))
+				// warning isn't reported, so failed reports don't produce new ones
+				logger.Warn("telemetry batch is dropped", logger.Fields{"sink": s.name(), "error": err.Error()})
+				break
+			}
+
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func anonymize(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.ToLower(value)))
+
+	return "anon:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// loadSalt reads secret of the node installation from work dir and makes it on the first call.
+// If it can't be kept, random secret is returned with error, so hashes are not linked between restarts.
+func loadSalt() ([]byte, error) {
+	const location = "telemetry.loadSalt->"
+
+	newSalt := make([]byte, saltSize)
+
+	_, err := rand.Read(newSalt)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	if paths.List().WorkDir == "" {
+		return newSalt, nil
+	}
+
+	saltPath := filepath.Join(paths.List().WorkDir, saltFileName)
+
+	saltHex, err := os.ReadFile(saltPath)
+	if err == nil {
+		stored, err := hex.DecodeString(strings.TrimSpace(string(saltHex)))
+		if err == nil && len(stored) == saltSize {
+			return stored, nil
+		}
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return newSalt, logger.MarkLocation(location, err)
+	}
+
+	err = os.WriteFile(saltPath, []byte(hex.EncodeToString(newSalt)), 0600)
+	if err != nil {
+		return newSalt, logger.MarkLocation(location, err)
+	}
+
+	return newSalt, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// scrub hashes addresses, removes ip addresses and home dir from text.
+func scrub(key []byte, text string) string {
+	if text == "" {
+		return text
+	}
+
+	text = regAddr.ReplaceAllStringFunc(text, func(address string) string {
+		return anonymize(key, address)
+	})
+
+	text = regIP.ReplaceAllString(text, "<ip>")
+
+	homeDir, err := os.UserHomeDir()
+	if err == nil && homeDir != "" && homeDir != "/" {
+		text = strings.ReplaceAll(text, homeDir, "~")
+	}
+
+	return text
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// errorSink records logged errors if error reports are switched on.
+type errorSink struct{}
+
+func (errorSink) Write(entry logger.Entry) error {
+	if entry.Level != logger.LevelError || atomic.LoadInt32(&errorReports) == 0 {
+		return nil
+	}
+
+	location, _ := entry.Fields["location"].(string)
+	network, _ := entry.Fields["network"].(string)
+
+	Record(Event{Time: entry.Time.Unix(), Type: Error, Network: network, Message: entry.Message, Location: location})
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
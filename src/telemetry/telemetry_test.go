@@ -0,0 +1,151 @@
+package telemetry_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	nodeAddress = "0x0000000000000000000000000000000000000001"
+	spAddress   = "0x00000000000000000000000000000000000000AB"
+)
+
+type collector struct {
+	mutex    sync.Mutex
+	batches  []telemetry.Batch
+	failures int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.failures > 0 {
+		c.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var batch telemetry.Batch
+
+	err := json.NewDecoder(r.Body).Decode(&batch)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.batches = append(c.batches, batch)
+}
+
+func (c *collector) events() []telemetry.Event {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	events := []telemetry.Event{}
+
+	for _, batch := range c.batches {
+		events = append(events, batch.Events...)
+	}
+
+	return events
+}
+
+func TestTelemetry(t *testing.T) {
+	c := &collector{failures: 1}
+
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	telemetryFile := filepath.Join(t.TempDir(), "telemetry.jsonl")
+
+	telemetry.Start(nodeAddress, nodeTypes.TelemetryConfig{Endpoint: server.URL, File: telemetryFile, BatchSize: 10, FlushInterval: 60})
+	telemetry.SetErrorReports(true)
+
+	telemetry.Stat(telemetry.Upload, "kovan", spAddress, "10.0.0.1", 100)
+	logger.Error(errors.New("failed to serve "+spAddress+" from 10.0.0.1:55000"), logger.Fields{"network": "kovan"})
+	logger.Warn("not reported", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := telemetry.Flush(ctx)
+	require.NoError(t, err)
+
+	events := c.events()
+	require.Len(t, events, 2)
+
+	require.Equal(t, telemetry.Upload, events[0].Type)
+	require.Equal(t, int64(100), events[0].Size)
+	require.Empty(t, events[0].RemoteAddr)
+	require.True(t, strings.HasPrefix(events[0].SP, "anon:"))
+
+	require.Equal(t, telemetry.Error, events[1].Type)
+	require.Equal(t, "kovan", events[1].Network)
+	require.NotContains(t, events[1].Message, spAddress)
+	require.NotContains(t, events[1].Message, "10.0.0.1")
+	require.Contains(t, events[1].Message, events[0].SP)
+
+	require.Equal(t, telemetry.SchemaVersion, c.batches[0].Schema)
+	require.NotEqual(t, nodeAddress, c.batches[0].Node)
+
+	fileBytes, err := os.ReadFile(telemetryFile)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(fileBytes)), "\n")
+	require.Len(t, lines, 1)
+
+	telemetry.SetConfig(nodeTypes.TelemetryConfig{Endpoint: server.URL, SendAddresses: true})
+	telemetry.SetErrorReports(false)
+
+	telemetry.Stat(telemetry.Download, "kovan", spAddress, "10.0.0.1", 200)
+	logger.Error(errors.New("not reported"), nil)
+
+	err = telemetry.Flush(ctx)
+	require.NoError(t, err)
+
+	events = c.events()
+	require.Len(t, events, 3)
+	require.Equal(t, spAddress, events[2].SP)
+	require.Equal(t, "10.0.0.1", events[2].RemoteAddr)
+	require.Equal(t, nodeAddress, c.batches[1].Node)
+}
+
+func TestAnonymizedWithSecret(t *testing.T) {
+	c := &collector{}
+
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	telemetry.Start(nodeAddress, nodeTypes.TelemetryConfig{Endpoint: server.URL})
+
+	telemetry.Stat(telemetry.Upload, "kovan", spAddress, "", 100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := telemetry.Flush(ctx)
+	require.NoError(t, err)
+
+	// hash without secret can be reversed by enumerating on-chain addresses
+	sum := sha256.Sum256([]byte(nodeAddress + strings.ToLower(spAddress)))
+	require.NotEqual(t, "anon:"+hex.EncodeToString(sum[:8]), c.events()[0].SP)
+
+	sum = sha256.Sum256([]byte(nodeAddress))
+	require.NotEqual(t, "anon:"+hex.EncodeToString(sum[:8]), c.batches[0].Node)
+}
@@ -0,0 +1,312 @@
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	certDirName  = "tls"
+	certFileName = "node.crt"
+	keyFileName  = "node.key"
+	certLifetime = 365 * 24 * time.Hour
+	renewBefore  = 30 * 24 * time.Hour
+)
+
+// BindingOID identifies certificate extension with node's signature of certificate public key.
+// Clients recover node's address from it, so self-signed certificate can be checked without CA.
+var BindingOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 58537, 1, 1}
+
+// Signer signs hash with node's private key.
+type Signer func(hash []byte) ([]byte, error)
+
+type reloader struct {
+	conf      nodeTypes.TLSConfig
+	sign      Signer
+	mutex     sync.Mutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	clientCAs *x509.CertPool
+	caMod     time.Time
+}
+
+// ServerConfig returns tls config for rpc server. Certificate and client CA files are checked
+// on each handshake and reloaded when changed, so they can be replaced without restarting the node.
+func ServerConfig(conf nodeTypes.TLSConfig, sign Signer) (*tls.Config, error) {
+	const location = "tlscert.ServerConfig->"
+
+	if (conf.CertFile == "") != (conf.KeyFile == "") {
+		return nil, logger.MarkLocation(location, errors.New("both certificate and key files must be set"))
+	}
+
+	if conf.CertFile == "" {
+		conf.CertFile = filepath.Join(paths.List().ConfigDir, certDirName, certFileName)
+		conf.KeyFile = filepath.Join(paths.List().ConfigDir, certDirName, keyFileName)
+	} else {
+		sign = nil
+	}
+
+	r := &reloader{conf: conf, sign: sign}
+
+	err := r.reload()
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.config()
+		},
+	}, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (r *reloader) config() (*tls.Config, error) {
+	const location = "tlscert.config->"
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	err := r.reload()
+	if err != nil {
+		// keep serving previous certificate, new one may be partially written
+		logger.Log(logger.MarkLocation(location, err))
+	}
+
+	tlsConf := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{"h2"},
+		Certificates: []tls.Certificate{*r.cert},
+	}
+
+	if r.clientCAs != nil {
+		tlsConf.ClientCAs = r.clientCAs
+		tlsConf.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConf, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// reload must be called with mutex locked or before reloader is shared.
+func (r *reloader) reload() error {
+	const location = "tlscert.reload->"
+
+	if r.sign != nil && r.needsRenewal() {
+		err := generate(r.conf.CertFile, r.conf.KeyFile, r.sign)
+		if err != nil {
+			return logger.MarkLocation(location, err)
+		}
+	}
+
+	certStat, err := os.Stat(r.conf.CertFile)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	keyStat, err := os.Stat(r.conf.KeyFile)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	certMod := certStat.ModTime()
+	if keyStat.ModTime().After(certMod) {
+		certMod = keyStat.ModTime()
+	}
+
+	if r.cert == nil || !certMod.Equal(r.certMod) {
+		cert, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+		if err != nil {
+			return logger.MarkLocation(location, err)
+		}
+
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return logger.MarkLocation(location, err)
+		}
+
+		r.cert = &cert
+		r.certMod = certMod
+	}
+
+	if r.conf.ClientCAFile == "" {
+		return nil
+	}
+
+	caStat, err := os.Stat(r.conf.ClientCAFile)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if r.clientCAs != nil && caStat.ModTime().Equal(r.caMod) {
+		return nil
+	}
+
+	caBytes, err := os.ReadFile(r.conf.ClientCAFile)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caBytes) {
+		return logger.MarkLocation(location, errors.New("no certificates found in "+r.conf.ClientCAFile))
+	}
+
+	r.clientCAs = clientCAs
+	r.caMod = caStat.ModTime()
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (r *reloader) needsRenewal() bool {
+	if r.cert != nil {
+		return time.Until(r.cert.Leaf.NotAfter) < renewBefore
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+	if err != nil {
+		return true
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return true
+	}
+
+	return time.Until(leaf.NotAfter) < renewBefore
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// generate creates self-signed certificate with node's address as common name and
+// node's signature of certificate public key in binding extension.
+func generate(certFile, keyFile string, sign Signer) error {
+	const location = "tlscert.generate->"
+
+	tlsKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&tlsKey.PublicKey)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	publicKeyHash := sha256.Sum256(publicKeyBytes)
+
+	binding, err := sign(publicKeyHash[:])
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	nodeAddress, err := recoverAddress(publicKeyHash[:], binding)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: nodeAddress.String()},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(certLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		ExtraExtensions: []pkix.Extension{
+			{Id: BindingOID, Value: binding},
+		},
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &tlsKey.PublicKey, tlsKey)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(tlsKey)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(certFile), 0700)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0600)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// NodeAddress returns address of the node that signed certificate public key.
+func NodeAddress(cert *x509.Certificate) (common.Address, error) {
+	const location = "tlscert.NodeAddress->"
+
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(BindingOID) {
+			continue
+		}
+
+		publicKeyHash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+		address, err := recoverAddress(publicKeyHash[:], ext.Value)
+		if err != nil {
+			return common.Address{}, logger.MarkLocation(location, err)
+		}
+
+		return address, nil
+	}
+
+	return common.Address{}, logger.MarkLocation(location, errors.New("certificate is not bound to node address"))
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func recoverAddress(hash, signature []byte) (common.Address, error) {
+	publicKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,170 @@
+package tlscert_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DeNetPRO/src/config"
+	"github.com/DeNetPRO/src/encryption"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	tlsCert "github.com/DeNetPRO/src/tls_cert"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	tstpkg.TestModeOn()
+	defer tstpkg.TestModeOff()
+
+	err := paths.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = config.Create(tstpkg.Data().AccAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	exitVal := m.Run()
+
+	err = os.RemoveAll(paths.List().WorkDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(exitVal)
+}
+
+func TestSelfSigned(t *testing.T) {
+	privateKeyBytes, err := encryption.DecryptAES(tstpkg.Data().EncrKey, tstpkg.Data().PKHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey, err := crypto.ToECDSA(privateKeyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, privateKey)
+	}
+
+	serverTLS, err := tlsCert.ServerConfig(nodeTypes.TLSConfig{Enabled: true}, sign)
+	require.NoError(t, err)
+
+	leaf := certificate(t, serverTLS)
+
+	require.Equal(t, common.HexToAddress(tstpkg.Data().AccAddr).String(), leaf.Subject.CommonName)
+
+	nodeAddress, err := tlsCert.NodeAddress(leaf)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress(tstpkg.Data().AccAddr), nodeAddress)
+
+	// generated certificate is reused on restart
+	serverTLS, err = tlsCert.ServerConfig(nodeTypes.TLSConfig{Enabled: true}, sign)
+	require.NoError(t, err)
+	require.Equal(t, leaf.Raw, certificate(t, serverTLS).Raw)
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+
+	conf := nodeTypes.TLSConfig{
+		Enabled:      true,
+		CertFile:     filepath.Join(dir, "node.crt"),
+		KeyFile:      filepath.Join(dir, "node.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}
+
+	writeCert(t, conf.CertFile, conf.KeyFile, "first")
+	writeCert(t, conf.ClientCAFile, filepath.Join(dir, "ca.key"), "ca")
+
+	serverTLS, err := tlsCert.ServerConfig(conf, nil)
+	require.NoError(t, err)
+
+	clientTLS, err := serverTLS.GetConfigForClient(nil)
+	require.NoError(t, err)
+	require.Equal(t, tls.VerifyClientCertIfGiven, clientTLS.ClientAuth)
+	require.Equal(t, "first", certificate(t, serverTLS).Subject.CommonName)
+
+	_, err = tlsCert.NodeAddress(certificate(t, serverTLS))
+	require.Error(t, err)
+
+	writeCert(t, conf.CertFile, conf.KeyFile, "second")
+
+	later := time.Now().Add(time.Minute)
+
+	err = os.Chtimes(conf.CertFile, later, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	require.Equal(t, "second", certificate(t, serverTLS).Subject.CommonName)
+}
+
+func certificate(t *testing.T, serverTLS *tls.Config) *x509.Certificate {
+	clientTLS, err := serverTLS.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(clientTLS.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return leaf
+}
+
+func writeCert(t *testing.T, certFile, keyFile, commonName string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
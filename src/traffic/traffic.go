@@ -0,0 +1,290 @@
+package traffic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DeNetPRO/src/errs"
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	stateFileName = "traffic.json"
+	saveInterval  = 10 * time.Second
+)
+
+// Record keeps traffic of storage provider in network, sizes are set in bytes.
+// Receipt is the last accepted signature of paid traffic.
+type Record struct {
+	Uploaded   uint64 `json:"uploaded"`
+	Downloaded uint64 `json:"downloaded"`
+	Paid       uint64 `json:"paid"`
+	Receipt    []byte `json:"receipt"`
+}
+
+var (
+	mutex         sync.Mutex
+	records       map[string]*Record
+	dirty         bool
+	nodeAddress   common.Address
+	trafficConfig nodeTypes.TrafficConfig
+)
+
+// Start sets node address that receipts are issued to and starts saving counters periodically
+// until ctx is done, counters are saved once more then.
+func Start(ctx context.Context, address string, conf nodeTypes.TrafficConfig) {
+	mutex.Lock()
+	nodeAddress = common.HexToAddress(address)
+	mutex.Unlock()
+
+	SetConfig(conf)
+
+	go saveLoop(ctx)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func saveLoop(ctx context.Context) {
+	const location = "traffic.saveLoop->"
+
+	ticker := time.NewTicker(saveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+		}
+
+		err := Save()
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// SetConfig sets paid traffic enforcement.
+func SetConfig(conf nodeTypes.TrafficConfig) {
+	mutex.Lock()
+	trafficConfig = conf
+	mutex.Unlock()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// ReceiptHash returns hash that storage provider signs to confirm paid traffic.
+func ReceiptHash(nodeAddress common.Address, network string, paid uint64) [32]byte {
+	paidBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(paidBytes, paid)
+
+	data := make([]byte, 0, common.AddressLength+len(network)+len(paidBytes))
+	data = append(data, nodeAddress.Bytes()...)
+	data = append(data, network...)
+	data = append(data, paidBytes...)
+
+	return sha256.Sum256(data)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// AddReceipt checks that paid traffic is signed by storage provider and keeps it if it's greater than the known one.
+func AddReceipt(network, spAddress string, paid uint64, signature []byte) error {
+	const location = "traffic.AddReceipt->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	receiptHash := ReceiptHash(nodeAddress, network, paid)
+
+	sigPublicKey, err := crypto.SigToPub(receiptHash[:], signature)
+	if err != nil {
+		return errs.List().Signature
+	}
+
+	if crypto.PubkeyToAddress(*sigPublicKey) != common.HexToAddress(spAddress) {
+		return errs.List().Signature
+	}
+
+	record, err := get(network, spAddress)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if paid <= record.Paid {
+		return nil
+	}
+
+	record.Paid = paid
+	record.Receipt = signature
+
+	err = save()
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Reserve counts size of part that is going to be served. If paid traffic is enforced and
+// storage provider's traffic would exceed it, Traffic error is returned and nothing is counted.
+func Reserve(network, spAddress string, size uint64) error {
+	const location = "traffic.Reserve->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	record, err := get(network, spAddress)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	if trafficConfig.Enforce && record.Downloaded+size > record.Paid+trafficConfig.FreeBytes {
+		return errs.List().Traffic
+	}
+
+	record.Downloaded += size
+	dirty = true
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// AddUploaded counts size of part uploaded by storage provider.
+func AddUploaded(network, spAddress string, size uint64) {
+	const location = "traffic.AddUploaded->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	record, err := get(network, spAddress)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+		return
+	}
+
+	record.Uploaded += size
+	dirty = true
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Info returns copy of storage provider's traffic record.
+func Info(network, spAddress string) (Record, error) {
+	const location = "traffic.Info->"
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	record, err := get(network, spAddress)
+	if err != nil {
+		return Record{}, logger.MarkLocation(location, err)
+	}
+
+	return *record, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Save writes counters to disk if they were changed.
+func Save() error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !dirty {
+		return nil
+	}
+
+	return save()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// get returns storage provider's record, records are loaded from disk on first use.
+// Must be called with mutex locked.
+func get(network, spAddress string) (*Record, error) {
+	const location = "traffic.get->"
+
+	if records == nil {
+		loaded := map[string]*Record{}
+
+		stateBytes, err := os.ReadFile(filepath.Join(paths.List().Storages[0], stateFileName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, logger.MarkLocation(location, err)
+		}
+
+		if err == nil {
+			err = json.Unmarshal(stateBytes, &loaded)
+			if err != nil {
+				return nil, logger.MarkLocation(location, err)
+			}
+		}
+
+		records = loaded
+	}
+
+	key := network + "/" + common.HexToAddress(spAddress).String()
+
+	record, ok := records[key]
+	if !ok {
+		record = &Record{}
+		records[key] = record
+	}
+
+	return record, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// save must be called with mutex locked.
+func save() error {
+	const location = "traffic.save->"
+
+	if records == nil {
+		return nil
+	}
+
+	stateBytes, err := json.Marshal(records)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	statePath := filepath.Join(paths.List().Storages[0], stateFileName)
+
+	tmpPath := statePath + ".tmp"
+
+	err = os.WriteFile(tmpPath, stateBytes, 0600)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	err = os.Rename(tmpPath, statePath)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	dirty = false
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,76 @@
+package traffic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DeNetPRO/src/encryption"
+	"github.com/DeNetPRO/src/errs"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/paths"
+	"github.com/DeNetPRO/src/traffic"
+	tstpkg "github.com/DeNetPRO/src/tst_pkg"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	network     = "kovan"
+	nodeAddress = "0x0000000000000000000000000000000000000001"
+)
+
+func TestPaidTraffic(t *testing.T) {
+	paths.SetStoragePaths([]string{t.TempDir()})
+
+	traffic.Start(context.Background(), nodeAddress, nodeTypes.TrafficConfig{Enforce: true, FreeBytes: 100})
+
+	spAddress := tstpkg.Data().AccAddr
+
+	err := traffic.Reserve(network, spAddress, 100)
+	require.NoError(t, err)
+
+	err = traffic.Reserve(network, spAddress, 1)
+	require.ErrorIs(t, err, errs.List().Traffic)
+
+	privateKeyBytes, err := encryption.DecryptAES(tstpkg.Data().EncrKey, tstpkg.Data().PKHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey, err := crypto.ToECDSA(privateKeyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receiptHash := traffic.ReceiptHash(common.HexToAddress(nodeAddress), network, 1000)
+
+	receipt, err := crypto.Sign(receiptHash[:], privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = traffic.AddReceipt(network, "0x0000000000000000000000000000000000000002", 1000, receipt)
+	require.ErrorIs(t, err, errs.List().Signature)
+
+	err = traffic.AddReceipt(network, spAddress, 2000, receipt)
+	require.ErrorIs(t, err, errs.List().Signature)
+
+	err = traffic.AddReceipt(network, spAddress, 1000, receipt)
+	require.NoError(t, err)
+
+	err = traffic.Reserve(network, spAddress, 1000)
+	require.NoError(t, err)
+
+	err = traffic.Reserve(network, spAddress, 1)
+	require.ErrorIs(t, err, errs.List().Traffic)
+
+	traffic.AddUploaded(network, spAddress, 500)
+
+	record, err := traffic.Info(network, spAddress)
+	require.NoError(t, err)
+	require.Equal(t, traffic.Record{Uploaded: 500, Downloaded: 1100, Paid: 1000, Receipt: receipt}, record)
+
+	err = traffic.Save()
+	require.NoError(t, err)
+}
@@ -0,0 +1,407 @@
+package upnp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DeNetPRO/src/logger"
+)
+
+const (
+	ssdpAddress = "239.255.255.250:1900"
+	ssdpWait    = 3 * time.Second
+
+	// IGD returns this code if it supports only permanent mappings
+	errOnlyPermanentLeases = 725
+)
+
+var igdDeviceTypes = []string{
+	"urn:schemas-upnp-org:device:InternetGatewayDevice:2",
+	"urn:schemas-upnp-org:device:InternetGatewayDevice:1",
+}
+
+var connectionServiceTypes = []string{
+	"urn:schemas-upnp-org:service:WANIPConnection:2",
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+// IGD is UPnP internet gateway device.
+type IGD struct {
+	controlURL  string
+	serviceType string
+	localIP     net.IP
+	httpClient  *http.Client
+}
+
+type deviceDescription struct {
+	URLBase string `xml:"URLBase"`
+	Device  device `xml:"device"`
+}
+
+type device struct {
+	DeviceType string    `xml:"deviceType"`
+	Services   []service `xml:"serviceList>service"`
+	Devices    []device  `xml:"deviceList>device"`
+}
+
+type service struct {
+	ServiceType string `xml:"serviceType"`
+	ControlURL  string `xml:"controlURL"`
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// DiscoverIGD looks for internet gateway device in local network with SSDP.
+func DiscoverIGD(ctx context.Context) (*IGD, error) {
+	const location = "upnp.DiscoverIGD->"
+
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+	defer conn.Close()
+
+	ssdpAddr, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	for _, deviceType := range igdDeviceTypes {
+		search := "M-SEARCH * HTTP/1.1\r\n" +
+			"HOST: " + ssdpAddress + "\r\n" +
+			"ST: " + deviceType + "\r\n" +
+			"MAN: \"ssdp:discover\"\r\n" +
+			"MX: 2\r\n\r\n"
+
+		_, err = conn.WriteTo([]byte(search), ssdpAddr)
+		if err != nil {
+			return nil, logger.MarkLocation(location, err)
+		}
+	}
+
+	deadline := time.Now().Add(ssdpWait)
+
+	ctxDeadline, ok := ctx.Deadline()
+	if ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	err = conn.SetReadDeadline(deadline)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	buf := make([]byte, 2048)
+
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil, logger.MarkLocation(location, errors.New("internet gateway device is not found"))
+		}
+
+		descriptionURL := ssdpLocation(buf[:n])
+		if descriptionURL == "" {
+			continue
+		}
+
+		igd, err := NewIGD(ctx, descriptionURL)
+		if err != nil {
+			logger.Debug("skipping upnp device", logger.Fields{"location": descriptionURL, "error": err.Error()})
+			continue
+		}
+
+		return igd, nil
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// ssdpLocation returns LOCATION header of SSDP response.
+func ssdpLocation(response []byte) string {
+	for _, line := range strings.Split(string(response), "\r\n") {
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(name), "location") {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// NewIGD reads device description from descriptionURL and finds its WAN connection service.
+func NewIGD(ctx context.Context, descriptionURL string) (*IGD, error) {
+	const location = "upnp.NewIGD->"
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, descriptionURL, nil)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, logger.MarkLocation(location, fmt.Errorf("device description request failed: %s", resp.Status))
+	}
+
+	var description deviceDescription
+
+	err = xml.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&description)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	connService, ok := findConnectionService(description.Device)
+	if !ok {
+		return nil, logger.MarkLocation(location, errors.New("device has no wan connection service"))
+	}
+
+	base := descriptionURL
+	if description.URLBase != "" {
+		base = description.URLBase
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	controlURL, err := baseURL.Parse(connService.ControlURL)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	localIP, err := localAddressTo(controlURL.Hostname())
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	igd := &IGD{
+		controlURL:  controlURL.String(),
+		serviceType: connService.ServiceType,
+		localIP:     localIP,
+		httpClient:  httpClient,
+	}
+
+	return igd, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func findConnectionService(d device) (service, bool) {
+	for _, serviceType := range connectionServiceTypes {
+		for _, s := range d.Services {
+			if s.ServiceType == serviceType {
+				return s, true
+			}
+		}
+	}
+
+	for _, child := range d.Devices {
+		s, ok := findConnectionService(child)
+		if ok {
+			return s, true
+		}
+	}
+
+	return service{}, false
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// localAddressTo returns local address that is used to reach host.
+func localAddressTo(host string) (net.IP, error) {
+	conn, err := net.Dial("udp4", net.JoinHostPort(host, "1"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (igd *IGD) Name() string {
+	return "upnp"
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (igd *IGD) ExternalIP(ctx context.Context) (net.IP, error) {
+	const location = "upnp.IGD.ExternalIP->"
+
+	resp, err := igd.soap(ctx, "GetExternalIPAddress", nil)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	address := soapValue(resp, "NewExternalIPAddress")
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, logger.MarkLocation(location, fmt.Errorf("device returned invalid address %q", address))
+	}
+
+	return ip, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// AddMapping maps external tcp port to the same port of this host. Lease is made permanent
+// if device doesn't support leases, it's removed on shutdown then.
+func (igd *IGD) AddMapping(ctx context.Context, port int, description string, lifetime time.Duration) (time.Duration, error) {
+	const location = "upnp.IGD.AddMapping->"
+
+	args := func(lease time.Duration) [][2]string {
+		return [][2]string{
+			{"NewRemoteHost", ""},
+			{"NewExternalPort", strconv.Itoa(port)},
+			{"NewProtocol", "TCP"},
+			{"NewInternalPort", strconv.Itoa(port)},
+			{"NewInternalClient", igd.localIP.String()},
+			{"NewEnabled", "1"},
+			{"NewPortMappingDescription", description},
+			{"NewLeaseDuration", strconv.Itoa(int(lease.Seconds()))},
+		}
+	}
+
+	_, err := igd.soap(ctx, "AddPortMapping", args(lifetime))
+
+	var upnpErr *soapError
+	if errors.As(err, &upnpErr) && upnpErr.code == errOnlyPermanentLeases {
+		lifetime = 0
+		_, err = igd.soap(ctx, "AddPortMapping", args(lifetime))
+	}
+
+	if err != nil {
+		return 0, logger.MarkLocation(location, err)
+	}
+
+	return lifetime, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (igd *IGD) DeleteMapping(ctx context.Context, port int) error {
+	const location = "upnp.IGD.DeleteMapping->"
+
+	_, err := igd.soap(ctx, "DeletePortMapping", [][2]string{
+		{"NewRemoteHost", ""},
+		{"NewExternalPort", strconv.Itoa(port)},
+		{"NewProtocol", "TCP"},
+	})
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+type soapError struct {
+	code        int
+	description string
+}
+
+func (e *soapError) Error() string {
+	return fmt.Sprintf("upnp error %d: %s", e.code, e.description)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// soap calls action of connection service, arguments are sent in the given order.
+func (igd *IGD) soap(ctx context.Context, action string, args [][2]string) ([]byte, error) {
+	body := &bytes.Buffer{}
+
+	body.WriteString(`<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" ` +
+		`s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(body, `<u:%s xmlns:u="%s">`, action, igd.serviceType)
+
+	for _, arg := range args {
+		fmt.Fprintf(body, "<%s>", arg[0])
+		xml.EscapeText(body, []byte(arg[1]))
+		fmt.Fprintf(body, "</%s>", arg[0])
+	}
+
+	fmt.Fprintf(body, "</u:%s></s:Body></s:Envelope>", action)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, igd.controlURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", `"`+igd.serviceType+"#"+action+`"`)
+
+	resp, err := igd.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		code, err := strconv.Atoi(soapValue(respBody, "errorCode"))
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %s", action, resp.Status)
+		}
+
+		return nil, &soapError{code: code, description: soapValue(respBody, "errorDescription")}
+	}
+
+	return respBody, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// soapValue returns text of the first element with name, namespaces are ignored.
+func soapValue(body []byte, name string) string {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != name {
+			continue
+		}
+
+		var value string
+
+		err = decoder.DecodeElement(&value, &start)
+		if err != nil {
+			return ""
+		}
+
+		return strings.TrimSpace(value)
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,226 @@
+package upnp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/DeNetPRO/src/logger"
+)
+
+const (
+	natPMPPort = "5351"
+
+	natPMPOpExternalAddress = 0
+	natPMPOpMapTCP          = 2
+
+	// request is resent with doubled timeout, starting from natPMPInitialTimeout
+	natPMPInitialTimeout = 250 * time.Millisecond
+	natPMPAttempts       = 5
+)
+
+var natPMPResults = map[uint16]string{
+	1: "unsupported version",
+	2: "not authorized",
+	3: "network failure",
+	4: "out of resources",
+	5: "unsupported opcode",
+}
+
+// NATPMP is gateway that supports NAT Port Mapping Protocol.
+type NATPMP struct {
+	gateway string
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// NewNATPMP makes NAT-PMP client for gateway address, port 5351 is used if it's not set.
+// Default gateway is used if address is empty.
+func NewNATPMP(gateway string) (*NATPMP, error) {
+	const location = "upnp.NewNATPMP->"
+
+	if gateway == "" {
+		ip, err := defaultGateway()
+		if err != nil {
+			return nil, logger.MarkLocation(location, err)
+		}
+
+		gateway = ip.String()
+	}
+
+	_, _, err := net.SplitHostPort(gateway)
+	if err != nil {
+		gateway = net.JoinHostPort(gateway, natPMPPort)
+	}
+
+	return &NATPMP{gateway: gateway}, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (n *NATPMP) Name() string {
+	return "natpmp"
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (n *NATPMP) ExternalIP(ctx context.Context) (net.IP, error) {
+	const location = "upnp.NATPMP.ExternalIP->"
+
+	resp, err := n.request(ctx, []byte{0, natPMPOpExternalAddress}, 12)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	return net.IPv4(resp[8], resp[9], resp[10], resp[11]), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// AddMapping maps external tcp port to the same port of this host, gateway may shorten lifetime.
+func (n *NATPMP) AddMapping(ctx context.Context, port int, description string, lifetime time.Duration) (time.Duration, error) {
+	const location = "upnp.NATPMP.AddMapping->"
+
+	resp, err := n.mapPort(ctx, port, port, lifetime)
+	if err != nil {
+		return 0, logger.MarkLocation(location, err)
+	}
+
+	mappedPort := int(binary.BigEndian.Uint16(resp[10:12]))
+	if mappedPort != port {
+		n.mapPort(ctx, port, 0, 0)
+		return 0, logger.MarkLocation(location, fmt.Errorf("gateway mapped port %d instead of %d", mappedPort, port))
+	}
+
+	return time.Duration(binary.BigEndian.Uint32(resp[12:16])) * time.Second, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func (n *NATPMP) DeleteMapping(ctx context.Context, port int) error {
+	const location = "upnp.NATPMP.DeleteMapping->"
+
+	_, err := n.mapPort(ctx, port, 0, 0)
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	return nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// mapPort sends mapping request, zero lifetime and external port remove mapping.
+func (n *NATPMP) mapPort(ctx context.Context, internalPort, externalPort int, lifetime time.Duration) ([]byte, error) {
+	req := make([]byte, 12)
+	req[1] = natPMPOpMapTCP
+	binary.BigEndian.PutUint16(req[4:6], uint16(internalPort))
+	binary.BigEndian.PutUint16(req[6:8], uint16(externalPort))
+	binary.BigEndian.PutUint32(req[8:12], uint32(lifetime.Seconds()))
+
+	return n.request(ctx, req, 16)
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// request sends request to gateway until response of respSize bytes is received.
+func (n *NATPMP) request(ctx context.Context, req []byte, respSize int) ([]byte, error) {
+	conn, err := net.Dial("udp4", n.gateway)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp := make([]byte, 16)
+	timeout := natPMPInitialTimeout
+
+	for i := 0; i < natPMPAttempts; i++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		_, err = conn.Write(req)
+		if err != nil {
+			return nil, err
+		}
+
+		deadline := time.Now().Add(timeout)
+
+		ctxDeadline, ok := ctx.Deadline()
+		if ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+
+		conn.SetReadDeadline(deadline)
+
+		size, err := conn.Read(resp)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				timeout *= 2
+				continue
+			}
+
+			return nil, err
+		}
+
+		if size < respSize || resp[0] != 0 || resp[1] != req[1]+128 {
+			continue
+		}
+
+		result := binary.BigEndian.Uint16(resp[2:4])
+		if result != 0 {
+			return nil, fmt.Errorf("nat-pmp error %d: %s", result, natPMPResults[result])
+		}
+
+		return resp[:respSize], nil
+	}
+
+	return nil, errors.New("nat-pmp gateway doesn't respond")
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// defaultGateway reads default route on linux, on other systems gateway is assumed
+// to have the first address in local network.
+func defaultGateway() (net.IP, error) {
+	file, err := os.Open("/proc/net/route")
+	if err == nil {
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 3 || fields[1] != "00000000" {
+				continue
+			}
+
+			gateway, err := hex.DecodeString(fields[2])
+			if err != nil || len(gateway) != 4 {
+				continue
+			}
+
+			// route table keeps addresses in little endian
+			return net.IPv4(gateway[3], gateway[2], gateway[1], gateway[0]), nil
+		}
+	}
+
+	localIP, err := localAddressTo("8.8.8.8")
+	if err != nil {
+		return nil, err
+	}
+
+	localIP = localIP.To4()
+
+	return net.IPv4(localIP[0], localIP[1], localIP[2], 1), nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -1,22 +1,244 @@
 package upnp
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	fastupnp "github.com/DeNetPRO/srcPRO/turbo-upnp"
+	"github.com/DeNetPRO/src/logger"
+	nodeTypes "github.com/DeNetPRO/src/node_types"
 )
 
-var InternetDevice *fastupnp.Device
+// Gateway maps ports of this host to the internet.
+type Gateway interface {
+	Name() string
+	ExternalIP(ctx context.Context) (net.IP, error)
+	// AddMapping returns lifetime that gateway granted, zero means permanent mapping.
+	AddMapping(ctx context.Context, port int, description string, lifetime time.Duration) (time.Duration, error)
+	DeleteMapping(ctx context.Context, port int) error
+}
+
+// Status Mapped is set if gateway accepted port mapping, it doesn't mean that node is reachable from the internet,
+// e.g. gateway itself may be behind another NAT, so reachability is checked by probe. Times are set in unix seconds.
+type Status struct {
+	Method          string `json:"method,omitempty"`
+	ExternalAddress string `json:"externalAddress,omitempty"`
+	Mapped          bool   `json:"mapped"`
+	ExpiresAt       int64  `json:"expiresAt,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+const (
+	mappingDescription = "DeNet node"
+
+	defaultLifetime = time.Hour
+	retryInterval   = 5 * time.Minute
+	deleteTimeout   = 5 * time.Second
+)
+
+var (
+	mutex   sync.Mutex
+	gateway Gateway
+	status  = Status{Error: "port mapping is not started"}
+)
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Discover looks for UPnP internet gateway device, NAT-PMP gateway is used if it's not found.
+// Device description url and NAT-PMP gateway address can be set in conf if discovery doesn't work.
+func Discover(ctx context.Context, conf nodeTypes.NATConfig) (Gateway, error) {
+	const location = "upnp.Discover->"
+
+	var igd *IGD
+	var err error
+
+	if conf.IGD != "" {
+		igd, err = NewIGD(ctx, conf.IGD)
+	} else {
+		igd, err = DiscoverIGD(ctx)
+	}
+
+	if err == nil {
+		return igd, nil
+	}
+
+	logger.Debug("upnp gateway is not found", logger.Fields{"error": err.Error()})
+
+	natPMP, err := NewNATPMP(conf.Gateway)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	_, err = natPMP.ExternalIP(ctx)
+	if err != nil {
+		return nil, logger.MarkLocation(location, errors.New("neither upnp nor nat-pmp gateway is found"))
+	}
+
+	return natPMP, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// ExternalIP returns address of gateway that maps node port, gateway is discovered if port is not mapped yet.
+func ExternalIP(ctx context.Context) (net.IP, error) {
+	const location = "upnp.ExternalIP->"
+
+	mutex.Lock()
+	current := gateway
+	mutex.Unlock()
+
+	if current == nil {
+		var err error
+
+		current, err = Discover(ctx, nodeTypes.NATConfig{})
+		if err != nil {
+			return nil, logger.MarkLocation(location, err)
+		}
+	}
+
+	ip, err := current.ExternalIP(ctx)
+	if err != nil {
+		return nil, logger.MarkLocation(location, err)
+	}
+
+	return ip, nil
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Current returns port mapping status.
+func Current() Status {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return status
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// Run maps node port on gateway and renews mapping until ctx is done, then mapping is removed.
+// Remember to enable UPnP or NAT-PMP on your router.
+func Run(ctx context.Context, conf nodeTypes.NATConfig, httpPort string) error {
+	const location = "upnp.Run->"
+
+	port, err := strconv.Atoi(strings.TrimPrefix(httpPort, ":"))
+	if err != nil {
+		return logger.MarkLocation(location, err)
+	}
+
+	lifetime := defaultLifetime
+	if conf.Lifetime > 0 {
+		lifetime = time.Duration(conf.Lifetime) * time.Second
+	}
+
+	var current Gateway
+
+	defer func() {
+		if current == nil {
+			return
+		}
+
+		deleteCtx, cancel := context.WithTimeout(context.Background(), deleteTimeout)
+		defer cancel()
+
+		err := current.DeleteMapping(deleteCtx, port)
+		if err != nil {
+			logger.Log(logger.MarkLocation(location, err))
+		}
+
+		setStatus(nil, Status{Error: "port mapping is removed"})
+	}()
+
+	for {
+		wait := retryInterval
+
+		if current == nil {
+			fmt.Println("Checking UPnP and NAT-PMP gateways...")
+
+			current, err = Discover(ctx, conf)
+			if err != nil && ctx.Err() == nil {
+				fmt.Println("Warn: gateway is not found, manual port forwarding may be needed")
+				setStatus(nil, Status{Error: err.Error()})
+			}
+		}
+
+		if current != nil {
+			granted, err := mapPort(ctx, current, port, lifetime)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				logger.Log(logger.MarkLocation(location, err))
+				setStatus(nil, Status{Method: current.Name(), Error: err.Error()})
+
+				// gateway may be replaced or restarted, so it's discovered again
+				current = nil
+			} else if granted > 0 {
+				wait = granted / 2
+			}
+		}
 
-// Initializes internet gateway device.
-// Remember to enable UPnP on your router.
-func Init() {
-	fmt.Println("Checking UPnP devices...")
+		timer := time.NewTimer(wait)
 
-	device, err := fastupnp.InitDevice()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+	}
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+// mapPort adds or renews mapping and returns lifetime granted by gateway.
+func mapPort(ctx context.Context, current Gateway, port int, lifetime time.Duration) (time.Duration, error) {
+	const location = "upnp.mapPort->"
+
+	granted, err := current.AddMapping(ctx, port, mappingDescription, lifetime)
 	if err != nil {
-		fmt.Println("Warn: manual port forwarding may be needed")
+		return 0, logger.MarkLocation(location, err)
 	}
 
-	InternetDevice = device
+	newStatus := Status{Method: current.Name(), Mapped: true}
+
+	if granted > 0 {
+		newStatus.ExpiresAt = time.Now().Add(granted).Unix()
+	}
+
+	ip, err := current.ExternalIP(ctx)
+	if err != nil {
+		logger.Log(logger.MarkLocation(location, err))
+	} else {
+		newStatus.ExternalAddress = net.JoinHostPort(ip.String(), strconv.Itoa(port))
+	}
+
+	mutex.Lock()
+	wasMapped := status.Mapped
+	mutex.Unlock()
+
+	if !wasMapped {
+		fmt.Println("Port", port, "is mapped with", current.Name(), newStatus.ExternalAddress)
+	}
+
+	setStatus(current, newStatus)
+
+	return granted, nil
 }
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
+
+func setStatus(current Gateway, newStatus Status) {
+	mutex.Lock()
+	gateway = current
+	status = newStatus
+	mutex.Unlock()
+}
+
+// ::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::::
@@ -0,0 +1,205 @@
+package upnp_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	nodeTypes "github.com/DeNetPRO/src/node_types"
+	"github.com/DeNetPRO/src/upnp"
+	"github.com/stretchr/testify/require"
+)
+
+const description = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+	<device>
+		<deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+		<deviceList>
+			<device>
+				<deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+				<deviceList>
+					<device>
+						<deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+						<serviceList>
+							<service>
+								<serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+								<controlURL>/ctl/IPConn</controlURL>
+							</service>
+						</serviceList>
+					</device>
+				</deviceList>
+			</device>
+		</deviceList>
+	</device>
+</root>`
+
+var regArg = regexp.MustCompile(`<(New[A-Za-z]+)>([^<]*)</`)
+
+// fakeIGD supports only permanent mappings like many home routers.
+type fakeIGD struct {
+	mutex    sync.Mutex
+	mappings map[string]string
+}
+
+func (igd *fakeIGD) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/rootDesc.xml" {
+		fmt.Fprint(w, description)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	args := map[string]string{}
+	for _, match := range regArg.FindAllStringSubmatch(string(body), -1) {
+		args[match[1]] = match[2]
+	}
+
+	igd.mutex.Lock()
+	defer igd.mutex.Unlock()
+
+	switch r.Header.Get("SOAPAction") {
+	case `"urn:schemas-upnp-org:service:WANIPConnection:1#GetExternalIPAddress"`:
+		fmt.Fprint(w, `<s:Envelope><s:Body><u:GetExternalIPAddressResponse><NewExternalIPAddress>203.0.113.5</NewExternalIPAddress></u:GetExternalIPAddressResponse></s:Body></s:Envelope>`)
+	case `"urn:schemas-upnp-org:service:WANIPConnection:1#AddPortMapping"`:
+		if args["NewLeaseDuration"] != "0" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `<s:Envelope><s:Body><s:Fault><detail><UPnPError><errorCode>725</errorCode><errorDescription>OnlyPermanentLeasesSupported</errorDescription></UPnPError></detail></s:Fault></s:Body></s:Envelope>`)
+			return
+		}
+
+		igd.mappings[args["NewExternalPort"]] = args["NewInternalClient"] + ":" + args["NewInternalPort"]
+	case `"urn:schemas-upnp-org:service:WANIPConnection:1#DeletePortMapping"`:
+		delete(igd.mappings, args["NewExternalPort"])
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (igd *fakeIGD) mapping(port string) (string, bool) {
+	igd.mutex.Lock()
+	defer igd.mutex.Unlock()
+
+	internal, ok := igd.mappings[port]
+	return internal, ok
+}
+
+func TestIGD(t *testing.T) {
+	fake := &fakeIGD{mappings: map[string]string{}}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	igd, err := upnp.NewIGD(context.Background(), server.URL+"/rootDesc.xml")
+	require.NoError(t, err)
+
+	ip, err := igd.ExternalIP(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.5", ip.String())
+
+	lifetime, err := igd.AddMapping(context.Background(), 55050, "test", time.Hour)
+	require.NoError(t, err)
+	require.Zero(t, lifetime)
+
+	internal, ok := fake.mapping("55050")
+	require.True(t, ok)
+	require.Equal(t, "127.0.0.1:55050", internal)
+
+	err = igd.DeleteMapping(context.Background(), 55050)
+	require.NoError(t, err)
+
+	_, ok = fake.mapping("55050")
+	require.False(t, ok)
+}
+
+func TestRun(t *testing.T) {
+	fake := &fakeIGD{mappings: map[string]string{}}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- upnp.Run(ctx, nodeTypes.NATConfig{IGD: server.URL + "/rootDesc.xml"}, ":55051")
+	}()
+
+	require.Eventually(t, func() bool {
+		return upnp.Current().Mapped
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := upnp.Current()
+	require.Equal(t, "upnp", status.Method)
+	require.Equal(t, "203.0.113.5:55051", status.ExternalAddress)
+
+	_, ok := fake.mapping("55051")
+	require.True(t, ok)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	_, ok = fake.mapping("55051")
+	require.False(t, ok)
+	require.False(t, upnp.Current().Mapped)
+}
+
+func TestNATPMP(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// fake gateway grants at most 10 minutes
+	go func() {
+		buf := make([]byte, 12)
+
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			switch {
+			case n == 2 && buf[1] == 0:
+				resp := make([]byte, 12)
+				resp[1] = 128
+				copy(resp[8:], net.IPv4(203, 0, 113, 6).To4())
+				conn.WriteTo(resp, addr)
+			case n == 12 && buf[1] == 2:
+				lifetime := binary.BigEndian.Uint32(buf[8:12])
+				if lifetime > 600 {
+					lifetime = 600
+				}
+
+				resp := make([]byte, 16)
+				resp[1] = 130
+				copy(resp[8:12], buf[4:8])
+				binary.BigEndian.PutUint32(resp[12:16], lifetime)
+				conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+
+	gateway, err := upnp.NewNATPMP(conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	ip, err := gateway.ExternalIP(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.6", ip.String())
+
+	lifetime, err := gateway.AddMapping(context.Background(), 55052, "test", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Minute, lifetime)
+
+	err = gateway.DeleteMapping(context.Background(), 55052)
+	require.NoError(t, err)
+
+}